@@ -9,17 +9,33 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect"
+	_ "github.com/ulule/mover/dialect/mysql"
+	_ "github.com/ulule/mover/dialect/postgres"
+	_ "github.com/ulule/mover/dialect/sqlite"
 	"github.com/ulule/mover/etl"
 )
 
 var (
-	tableName string
-	query     string
-	path      string
-	dsn       string
-	verbose   bool
-	version   bool
-	action    string
+	tableName   string
+	query       string
+	path        string
+	dsn         string
+	dialectName string
+	verbose     bool
+	version     bool
+	action      string
+	schemaCache string
+	refresh     bool
+	conflict    string
+	loadMode    string
+	atomic      bool
+	remap       bool
+	journal     string
+	verify      string
+	dryRun      bool
+	batchSize   int
+	workers     int
 )
 
 func main() {
@@ -27,7 +43,19 @@ func main() {
 	flag.StringVar(&tableName, "table", "", "table name to export")
 	flag.StringVar(&path, "path", "", "directory output")
 	flag.StringVar(&dsn, "dsn", "", "database dsn")
+	flag.StringVar(&dialectName, "dialect", "", fmt.Sprintf("database dialect %v (default: resolved from the dsn scheme)", dialect.Dialects()))
 	flag.StringVar(&action, "action", "", "action to execute")
+	flag.StringVar(&schemaCache, "schema-cache", "", "directory of the schema cache (default: schema_cache.path from the configuration)")
+	flag.BoolVar(&refresh, "refresh-schema", false, "introspect the database again and refresh the schema cache")
+	flag.StringVar(&conflict, "conflict", "", "conflict strategy of tables without their own strategy (nothing, skip_duplicates, append, update, replace, fail)")
+	flag.StringVar(&loadMode, "load-mode", "", "load mode (auto, disable_triggers, replica, deferred, ordered)")
+	flag.BoolVar(&atomic, "atomic", false, "load every table in a single transaction rolled back on any error")
+	flag.BoolVar(&remap, "remap", false, "load rows with new primary keys allocated from the target sequences")
+	flag.StringVar(&journal, "journal", "", "path of the load journal (default: journal.jsonl in the loaded directory)")
+	flag.StringVar(&verify, "verify", "", "verification of foreign keys once loaded (warn, strict, off)")
+	flag.BoolVar(&dryRun, "dry-run", false, "report what a load would change without writing anything")
+	flag.IntVar(&batchSize, "batch-size", 0, "number of rows decoded and inserted at once by loads (default: 1000)")
+	flag.IntVar(&workers, "workers", 0, "number of concurrent fetches of extractions (default: 1)")
 	flag.BoolVar(&verbose, "verbose", false, "verbose logs")
 	flag.BoolVar(&version, "version", false, "show version")
 	flag.Parse()
@@ -56,7 +84,47 @@ func main() {
 		}
 	}
 
-	engine, err := etl.NewEngine(ctx, cfg, dsn, logger)
+	if schemaCache != "" {
+		cfg.SchemaCache.Path = schemaCache
+	}
+	if refresh {
+		cfg.SchemaCache.Refresh = true
+	}
+	if conflict != "" {
+		cfg.Conflict = dialect.ConflictStrategy(conflict)
+	}
+	if loadMode != "" {
+		cfg.LoadMode = dialect.LoadMode(loadMode)
+	}
+	if atomic {
+		cfg.Atomic = true
+	}
+	if remap {
+		cfg.Remap = true
+	}
+	if journal != "" {
+		cfg.Journal = journal
+	}
+	if verify != "" {
+		cfg.Verify = config.VerifyMode(verify)
+	}
+	if dryRun {
+		cfg.DryRun = true
+	}
+	if batchSize > 0 {
+		cfg.BatchSize = batchSize
+	}
+	if workers > 0 {
+		cfg.Workers = workers
+	}
+
+	d, err := dialect.Open(ctx, dialectName, dsn)
+	if err != nil {
+		logger.Error("unable to initialize dialect", zap.Error(err), zap.String("dialect", dialectName))
+		return
+	}
+
+	engine, err := etl.NewEngineWithDialect(ctx, cfg, d, logger)
 	if err != nil {
 		logger.Error("unable to initialize engine", zap.Error(err))
 		return
@@ -81,6 +149,18 @@ func main() {
 				zap.Error(err),
 				zap.String("path", path))
 		}
+	case "unload":
+		if err := engine.Unload(ctx, path); err != nil {
+			logger.Error("unable to unload data",
+				zap.Error(err),
+				zap.String("path", path))
+		}
+	case "verify":
+		if err := engine.Verify(ctx, path); err != nil {
+			logger.Error("unable to verify data",
+				zap.Error(err),
+				zap.String("path", path))
+		}
 	case "describe":
 		table, err := engine.Describe(ctx, tableName)
 		if err != nil {
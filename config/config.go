@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 
@@ -36,19 +37,95 @@ type Column struct {
 	Download *Download `json:"download"`
 }
 
+// ForeignKey declares a virtual foreign key, mostly on views which have no constraint.
+type ForeignKey struct {
+	Name                  string   `json:"name"`
+	ColumnNames           []string `json:"column_names"`
+	ReferencedTableName   string   `json:"referenced_table_name"`
+	ReferencedColumnNames []string `json:"referenced_column_names"`
+}
+
 type Schema struct {
-	TableName         string        `json:"table_name"`
-	OmitReferenceKeys bool          `json:"omit_reference_keys"`
-	ReferenceKeys     []string      `json:"reference_keys"`
-	Queries           []Query       `json:"queries"`
-	Columns           []Column      `json:"columns"`
-	Table             dialect.Table `json:"-"`
+	TableName         string   `json:"table_name"`
+	OmitReferenceKeys bool     `json:"omit_reference_keys"`
+	ReferenceKeys     []string `json:"reference_keys"`
+	Queries           []Query  `json:"queries"`
+	Columns           []Column `json:"columns"`
+	// PrimaryKeys and ForeignKeys declare virtual keys for views and tables without constraints,
+	// PrimaryKeys is ignored when the table already has a primary key.
+	PrimaryKeys []string     `json:"primary_keys"`
+	ForeignKeys []ForeignKey `json:"foreign_keys"`
+	// Conflict is the strategy used to load rows which already exist (nothing, skip_duplicates, append,
+	// update, replace or fail), tables without any key usually need skip_duplicates or append.
+	Conflict dialect.ConflictStrategy `json:"conflict"`
+	// UpdateColumns restricts the columns updated by the update strategy.
+	UpdateColumns []string      `json:"update_columns"`
+	Table         dialect.Table `json:"-"`
+}
+
+// SchemaCache configures the on-disk cache of the introspected tables.
+type SchemaCache struct {
+	// Path is the directory of the cache files, tables are introspected on every run when empty.
+	Path string `json:"path"`
+	// Refresh introspects the database again and replaces the cached tables.
+	Refresh bool `json:"refresh"`
+}
+
+// VerifyMode defines how rows referencing rows which do not exist are handled after a load.
+type VerifyMode string
+
+const (
+	// VerifyWarn logs the rows referencing rows which do not exist. It is the default mode.
+	VerifyWarn VerifyMode = "warn"
+	// VerifyStrict fails the load when rows reference rows which do not exist.
+	VerifyStrict VerifyMode = "strict"
+	// VerifyOff skips the verification.
+	VerifyOff VerifyMode = "off"
+)
+
+// Validate returns an error when the mode is unknown, an empty mode is valid.
+func (m VerifyMode) Validate() error {
+	switch m {
+	case "", VerifyWarn, VerifyStrict, VerifyOff:
+		return nil
+	}
+
+	return fmt.Errorf("unknown verify mode %s", m)
 }
 
 type Config struct {
 	Locale string   `json:"locale"`
 	Schema []Schema `json:"schema"`
 	Extra  []Schema `json:"extra"`
+	// Schemas lists the database schemas to introspect, the default schema of the dialect is used when empty.
+	// Tables outside the default schema are referenced by their qualified name (e.g. billing.invoice).
+	Schemas []string `json:"schemas"`
+	// SchemaCache caches the introspected tables between runs, they are reused as long as the
+	// schema fingerprint computed by the dialect does not change.
+	SchemaCache SchemaCache `json:"schema_cache"`
+	// Conflict is the strategy used to load rows which already exist for tables without their own strategy.
+	Conflict dialect.ConflictStrategy `json:"conflict"`
+	// LoadMode defines how rows referencing rows which are not loaded yet are loaded (auto,
+	// disable_triggers, replica, deferred or ordered), auto by default.
+	LoadMode dialect.LoadMode `json:"load_mode"`
+	// Atomic loads every table in a single transaction, nothing is loaded when any table fails to load.
+	Atomic bool `json:"atomic"`
+	// Remap loads rows with new primary keys allocated from the sequences of the target database,
+	// foreign keys referencing them are rewritten accordingly.
+	Remap bool `json:"remap"`
+	// Journal is the path of the journal recording the rows inserted by loads,
+	// journal.jsonl in the loaded directory by default.
+	Journal string `json:"journal"`
+	// Verify defines how foreign keys referencing rows which do not exist are handled once loaded
+	// (warn, strict or off), warn by default.
+	Verify VerifyMode `json:"verify"`
+	// DryRun reports the rows a load would insert, skip or change without writing anything.
+	DryRun bool `json:"dry_run"`
+	// BatchSize is the number of rows decoded and inserted at once by loads, 1000 by default.
+	BatchSize int `json:"batch_size"`
+	// Workers is the number of concurrent fetches of extractions, 1 by default. Workers share a consistent
+	// snapshot of the database, extractions are sequential with dialects which cannot share snapshots.
+	Workers int `json:"workers"`
 }
 
 // Load loads the configuration from configuration file path.
@@ -3,8 +3,30 @@ package dialect
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
+// QualifiedName returns the name of a table prefixed by its schema,
+// tables of the default schema are not prefixed.
+func QualifiedName(schema, name string) string {
+	if schema == "" {
+		return name
+	}
+
+	return schema + "." + name
+}
+
+// SplitName splits a qualified table name in schema and table names,
+// the schema is empty for tables of the default schema.
+func SplitName(qualifiedName string) (string, string) {
+	idx := strings.LastIndex(qualifiedName, ".")
+	if idx == -1 {
+		return "", qualifiedName
+	}
+
+	return qualifiedName[:idx], qualifiedName[idx+1:]
+}
+
 // Tables contains a set of tables.
 type Tables []Table
 
@@ -19,18 +41,95 @@ func (t Tables) Get(tableName string) Table {
 	return Table{}
 }
 
+// Link resolves the tables of reference keys and foreign keys among the set of tables.
+func (t Tables) Link() {
+	tables := make(map[string]Table, len(t))
+	for i := range t {
+		tables[t[i].Name] = t[i]
+	}
+
+	for i := range t {
+		for j := range t[i].ReferenceKeys {
+			t[i].ReferenceKeys[j].Table = tables[t[i].ReferenceKeys[j].TableName]
+		}
+
+		for j := range t[i].ForeignKeys {
+			t[i].ForeignKeys[j].ReferencedTable = tables[t[i].ForeignKeys[j].ReferencedTableName]
+		}
+	}
+}
+
+// TableKind is the kind of relation backing a Table.
+type TableKind string
+
+const (
+	// TableKindTable is a regular table.
+	TableKindTable TableKind = "table"
+	// TableKindPartitioned is a partitioned table, its partitions are not listed
+	// and rows are loaded through it.
+	TableKindPartitioned TableKind = "partitioned"
+	// TableKindView is a view, rows can be extracted but not loaded.
+	TableKindView TableKind = "view"
+	// TableKindMaterializedView is a materialized view, it is refreshed after loading.
+	TableKindMaterializedView TableKind = "materialized_view"
+	// TableKindForeign is a foreign table.
+	TableKindForeign TableKind = "foreign"
+)
+
 // Table contains the definition of a database table.
+//
+// Name is qualified by the schema when the table does not belong to the default schema
+// of the dialect (e.g. "billing.invoice").
 type Table struct {
 	Name          string
+	Schema        string
+	Kind          TableKind
 	PrimaryKeys   []PrimaryKey
+	UniqueKeys    []UniqueKey
 	Columns       Columns
 	ForeignKeys   ForeignKeys
 	ReferenceKeys ReferenceKeys
+	Sequences     []Sequence
 }
 
-// PrimaryKeyColumnName returns the primary key column name.
-func (t Table) PrimaryKeyColumnName() string {
-	return t.PrimaryKeys[0].Name
+// Insertable returns true when rows can be inserted in the table, views are extraction only.
+func (t Table) Insertable() bool {
+	return t.Kind != TableKindView && t.Kind != TableKindMaterializedView
+}
+
+// PrimaryKeyColumnNames returns the primary key column names in key order.
+func (t Table) PrimaryKeyColumnNames() []string {
+	names := make([]string, len(t.PrimaryKeys))
+	for i := range t.PrimaryKeys {
+		names[i] = t.PrimaryKeys[i].Name
+	}
+
+	return names
+}
+
+// KeyColumnNames returns the column names identifying a row: the primary key or the first
+// unique key when the table has no primary key. It returns nil when rows cannot be identified.
+func (t Table) KeyColumnNames() []string {
+	if len(t.PrimaryKeys) > 0 {
+		return t.PrimaryKeyColumnNames()
+	}
+
+	if len(t.UniqueKeys) > 0 {
+		return t.UniqueKeys[0].ColumnNames
+	}
+
+	return nil
+}
+
+// Sequence returns the sequence owned by a column, false when the column owns no sequence.
+func (t Table) Sequence(columnName string) (Sequence, bool) {
+	for i := range t.Sequences {
+		if t.Sequences[i].ColumnName == columnName {
+			return t.Sequences[i], true
+		}
+	}
+
+	return Sequence{}, false
 }
 
 // Columns contains a set of columns.
@@ -72,49 +171,343 @@ func (f PrimaryKey) String() string {
 	return fmt.Sprintf("%s(%s)", f.TableName, f.Name)
 }
 
-// ForeignKey contains the definition of a foreign key column.
-type ForeignKey struct {
-	Name                 string
-	Definition           string
+// ColumnReference pairs a column with the column it references.
+type ColumnReference struct {
 	ColumnName           string
-	ReferencedTableName  string
-	ReferencedTable      Table
 	ReferencedColumnName string
 }
 
+// ColumnReferences contains the ordered column pairs of a foreign key.
+type ColumnReferences []ColumnReference
+
+// ColumnNames returns the referencing column names.
+func (c ColumnReferences) ColumnNames() []string {
+	names := make([]string, len(c))
+	for i := range c {
+		names[i] = c[i].ColumnName
+	}
+
+	return names
+}
+
+// ReferencedColumnNames returns the referenced column names.
+func (c ColumnReferences) ReferencedColumnNames() []string {
+	names := make([]string, len(c))
+	for i := range c {
+		names[i] = c[i].ReferencedColumnName
+	}
+
+	return names
+}
+
+// Sequence contains the definition of a sequence owned by a column, such as serial and identity columns.
+// Name is qualified like table names.
+type Sequence struct {
+	Name       string
+	ColumnName string
+}
+
+// UniqueKey contains the definition of a unique constraint or index which is not the primary key.
+type UniqueKey struct {
+	Name        string
+	ColumnNames []string
+}
+
+// ForeignKey contains the definition of a foreign key, Columns are ordered as in the constraint.
+type ForeignKey struct {
+	Name                string
+	Definition          string
+	Columns             ColumnReferences
+	ReferencedTableName string
+	ReferencedTable     Table `json:"-"`
+}
+
 // String returns the string representation of a ForeignKey.
 func (f ForeignKey) String() string {
-	return fmt.Sprintf("%s(%s)", f.ReferencedTable.Name, f.ReferencedColumnName)
+	return fmt.Sprintf("%s(%s)", f.ReferencedTable.Name, strings.Join(f.Columns.ReferencedColumnNames(), ", "))
 }
 
 // ForeignKeys contains a set of ForeignKey.
 type ForeignKeys []ForeignKey
 
-// ReferenceKey contains the definition of a reference key.
+// ReferenceKey contains the definition of a reference key, Columns pair the columns of the
+// referencing table with the ones of the referenced table.
 type ReferenceKey struct {
-	Name       string
-	Table      Table
-	TableName  string
-	ColumnName string
+	Name      string
+	Table     Table `json:"-"`
+	TableName string
+	Columns   ColumnReferences
 }
 
 // String returns the string representation of a ReferenceKey.
 func (f ReferenceKey) String() string {
-	return fmt.Sprintf("%s(%s)", f.Table.Name, f.ColumnName)
+	return fmt.Sprintf("%s(%s)", f.Table.Name, strings.Join(f.Columns.ColumnNames(), ", "))
 }
 
 // ReferenceKeys contains a set of ReferenceKey.
 type ReferenceKeys []ReferenceKey
 
+// ConflictStrategy defines how BulkInsert handles rows which already exist.
+type ConflictStrategy string
+
+const (
+	// ConflictNothing skips rows conflicting on the primary key, or on any unique constraint
+	// when the table has no primary key. Rows of a table with a primary key conflicting on another
+	// unique constraint fail to load. It is the default strategy.
+	ConflictNothing ConflictStrategy = "nothing"
+	// ConflictSkipDuplicates skips rows identical to an existing row on all their columns,
+	// it suits tables without any key such as audit logs.
+	ConflictSkipDuplicates ConflictStrategy = "skip_duplicates"
+	// ConflictAppend inserts rows without checking for existing ones.
+	ConflictAppend ConflictStrategy = "append"
+	// ConflictUpdate updates rows conflicting on the key of the table (see Table.KeyColumnNames)
+	// with the loaded values.
+	ConflictUpdate ConflictStrategy = "update"
+	// ConflictReplace deletes rows conflicting on the key of the table before inserting the loaded ones.
+	ConflictReplace ConflictStrategy = "replace"
+	// ConflictFail inserts nothing and returns a ConflictError reporting the conflicting keys
+	// when loaded rows conflict on the key of the table. Loads check every batch of a file
+	// before inserting the first one.
+	ConflictFail ConflictStrategy = "fail"
+)
+
+// Validate returns an error when the strategy is unknown, an empty strategy is valid.
+func (c ConflictStrategy) Validate() error {
+	switch c {
+	case "", ConflictNothing, ConflictSkipDuplicates, ConflictAppend, ConflictUpdate, ConflictReplace, ConflictFail:
+		return nil
+	}
+
+	return fmt.Errorf("unknown conflict strategy %s", c)
+}
+
+// InsertOptions contains the options of BulkInsert.
+type InsertOptions struct {
+	Conflict ConflictStrategy
+	// UpdateColumns restricts the columns updated by ConflictUpdate, all the loaded columns
+	// but the key ones are updated when empty.
+	UpdateColumns []string
+	// Inserted is called with the key of every inserted row (see Table.KeyColumnNames) by dialects
+	// implementing Unloader, rows which already existed are not reported.
+	Inserted func(key []interface{})
+}
+
+// UpdateColumnNames returns the loaded columns updated by ConflictUpdate.
+func (o InsertOptions) UpdateColumnNames(table Table, columnNames []string) []string {
+	keys := make(map[string]struct{})
+	for _, name := range table.KeyColumnNames() {
+		keys[name] = struct{}{}
+	}
+
+	selected := make(map[string]struct{}, len(o.UpdateColumns))
+	for _, name := range o.UpdateColumns {
+		selected[name] = struct{}{}
+	}
+
+	results := make([]string, 0, len(columnNames))
+	for _, name := range columnNames {
+		if _, ok := keys[name]; ok {
+			continue
+		}
+
+		if _, ok := selected[name]; len(selected) > 0 && !ok {
+			continue
+		}
+
+		results = append(results, name)
+	}
+
+	return results
+}
+
+// ConflictStrategy returns the conflict strategy, ConflictNothing by default.
+func (o InsertOptions) ConflictStrategy() ConflictStrategy {
+	if o.Conflict == "" {
+		return ConflictNothing
+	}
+
+	return o.Conflict
+}
+
+// LoadMode defines how rows are loaded while the rows they reference are not loaded yet.
+type LoadMode string
+
+const (
+	// LoadModeAuto selects the first mode allowed by the privileges of the user, in the order
+	// disable_triggers, replica and deferred. The deferred mode is only selected when the foreign keys
+	// of the loaded tables are declared as DEFERRABLE, the ordered mode is selected otherwise.
+	// It is the default mode.
+	LoadModeAuto LoadMode = "auto"
+	// LoadModeDisableTriggers disables the triggers of every loaded table, foreign keys included.
+	// It requires superuser privileges with PostgreSQL.
+	LoadModeDisableTriggers LoadMode = "disable_triggers"
+	// LoadModeReplica sets session_replication_role to replica, triggers and foreign keys
+	// are not fired while loading.
+	LoadModeReplica LoadMode = "replica"
+	// LoadModeDeferred loads every table in a single transaction with deferred constraints,
+	// only constraints declared as DEFERRABLE are deferred.
+	LoadModeDeferred LoadMode = "deferred"
+	// LoadModeOrdered keeps triggers and constraints enabled, tables are loaded in dependency order.
+	LoadModeOrdered LoadMode = "ordered"
+)
+
+// Validate returns an error when the mode is unknown, an empty mode is valid.
+func (m LoadMode) Validate() error {
+	switch m {
+	case "", LoadModeAuto, LoadModeDisableTriggers, LoadModeReplica, LoadModeDeferred, LoadModeOrdered:
+		return nil
+	}
+
+	return fmt.Errorf("unknown load mode %s", m)
+}
+
+// Unloader is implemented by dialects reporting the rows inserted by BulkInsert, see InsertOptions.Inserted.
+type Unloader interface {
+	// BulkDelete deletes the rows identified by the key of the table (see Table.KeyColumnNames).
+	BulkDelete(context.Context, Table, [][]interface{}) error
+}
+
+// IntegrityChecker is implemented by dialects able to find rows referencing rows which do not exist.
+type IntegrityChecker interface {
+	// DanglingReferences returns the number of rows of a table whose foreign key references a row which
+	// does not exist, and at most limit of these rows. Foreign keys with a NULL column reference no row.
+	DanglingReferences(ctx context.Context, table Table, foreignKey ForeignKey, limit int) (int64, []map[string]interface{}, error)
+}
+
+// DanglingReferencesClause returns the FROM and WHERE clauses selecting the rows of a table aliased as c
+// whose foreign key references a row which does not exist, identifiers are quoted with quote.
+func DanglingReferencesClause(table Table, foreignKey ForeignKey, quote func(string) string) string {
+	var (
+		notNull = make([]string, len(foreignKey.Columns))
+		matches = make([]string, len(foreignKey.Columns))
+	)
+	for i, reference := range foreignKey.Columns {
+		notNull[i] = fmt.Sprintf("c.%s IS NOT NULL", quote(reference.ColumnName))
+		matches[i] = fmt.Sprintf("p.%s = c.%s", quote(reference.ReferencedColumnName), quote(reference.ColumnName))
+	}
+
+	return fmt.Sprintf("FROM %s c WHERE %s AND NOT EXISTS (SELECT 1 FROM %s p WHERE %s)",
+		quote(table.Name), strings.Join(notNull, " AND "),
+		quote(foreignKey.ReferencedTableName), strings.Join(matches, " AND "))
+}
+
+// Snapshotter is implemented by dialects sharing a consistent snapshot of the database between concurrent
+// readers, the ResultSet calls between BeginSnapshot and EndSnapshot read the same snapshot.
+type Snapshotter interface {
+	// BeginSnapshot opens at most workers readers of a single snapshot and returns the number of workers
+	// allowed to read concurrently.
+	BeginSnapshot(ctx context.Context, workers int) (int, error)
+	// EndSnapshot closes the readers of the snapshot.
+	EndSnapshot(ctx context.Context) error
+}
+
+// ArrayBinder is implemented by dialects binding a slice to a single array parameter, rows matching many
+// values of a column are selected with "column = ANY($1)" instead of one parameter per value.
+type ArrayBinder interface {
+	BindsArrays() bool
+}
+
+// KeyAllocator is implemented by dialects able to allocate keys from the sequences of tables.
+type KeyAllocator interface {
+	// AllocateKeys returns count new values of the sequence owned by a column of a table.
+	AllocateKeys(ctx context.Context, table Table, columnName string, count int) ([]int64, error)
+}
+
+// Updater is implemented by dialects able to update loaded rows.
+type Updater interface {
+	// BulkUpdate sets the columns of existing rows identified by the key of the table (see Table.KeyColumnNames).
+	BulkUpdate(context.Context, Table, []map[string]interface{}) error
+}
+
+// LoadOptions configures the load of a dump.
+type LoadOptions struct {
+	Mode LoadMode
+	// Atomic loads every table in a single transaction, sequence resets included,
+	// which is rolled back when any table fails to load.
+	Atomic bool
+	// Tables are the loaded tables, LoadModeAuto checks whether their foreign keys can be deferred.
+	Tables Tables
+}
+
+// LoadSession is implemented by dialects supporting load modes, the BulkInsert calls
+// between BeginLoad and EndLoad load the tables of a dump.
+type LoadSession interface {
+	// BeginLoad starts a load and returns the mode selected for it.
+	BeginLoad(context.Context, LoadOptions) (LoadMode, error)
+	// EndLoad ends a load, its changes are rolled back when the load failed with the given error.
+	EndLoad(context.Context, error) error
+}
+
+// ConflictError is returned by BulkInsert with ConflictFail when loaded rows conflict with existing rows.
+type ConflictError struct {
+	TableName   string
+	ColumnNames []string
+	// Keys contains the values of the key columns of every conflicting row.
+	Keys [][]interface{}
+}
+
+// maxReportedKeys is the number of conflicting keys listed in the message of a ConflictError.
+const maxReportedKeys = 10
+
+func (e *ConflictError) Error() string {
+	keys := make([]string, 0, maxReportedKeys+1)
+	for i := range e.Keys {
+		if i == maxReportedKeys {
+			keys = append(keys, fmt.Sprintf("and %d more", len(e.Keys)-maxReportedKeys))
+			break
+		}
+
+		values := make([]string, len(e.Keys[i]))
+		for j := range e.Keys[i] {
+			values[j] = fmt.Sprint(e.Keys[i][j])
+		}
+		keys = append(keys, "("+strings.Join(values, ", ")+")")
+	}
+
+	return fmt.Sprintf("%d rows conflict with existing rows of table %s on (%s): %s",
+		len(e.Keys), e.TableName, strings.Join(e.ColumnNames, ", "), strings.Join(keys, ", "))
+}
+
+// RowError is returned by BulkInsert when a row cannot be loaded.
+type RowError struct {
+	TableName string
+	// Index is the position of the row in the loaded rows.
+	Index int
+	Row   map[string]interface{}
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("unable to load row %d of table %s %v: %s", e.Index, e.TableName, e.Row, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// MaterializedViewRefresher is implemented by dialects supporting materialized views,
+// they are refreshed once data has been loaded.
+type MaterializedViewRefresher interface {
+	RefreshMaterializedView(context.Context, Table) error
+}
+
+// SchemaFingerprinter is implemented by dialects whose introspected tables can be cached,
+// the fingerprint changes whenever a table, a column, a constraint or a sequence changes.
+type SchemaFingerprinter interface {
+	// Identity identifies the database server and database without credentials.
+	Identity() string
+	SchemaFingerprint(context.Context, ...string) (string, error)
+}
+
 // Dialect is the main interface to interact with RDMS.
 type Dialect interface {
 	Close(context.Context) error
 	ReferenceKeys(context.Context, string) (ReferenceKeys, error)
 	ForeignKeys(context.Context, string) (ForeignKeys, error)
 	PrimaryKeyConstraint(context.Context, string) (string, error)
-	Tables(context.Context) (Tables, error)
+	Tables(context.Context, ...string) (Tables, error)
 	Table(context.Context, string) (Table, error)
 	Columns(context.Context, string) ([]Column, error)
-	BulkInsert(context.Context, Table, []map[string]interface{}) error
+	BulkInsert(context.Context, Table, []map[string]interface{}, InsertOptions) error
 	ResultSet(context.Context, string, ...interface{}) ([]map[string]interface{}, error)
 }
@@ -0,0 +1,27 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateColumnNames(t *testing.T) {
+	table := Table{
+		Name:        "user",
+		PrimaryKeys: []PrimaryKey{{Name: "id"}},
+	}
+
+	assert.Equal(t, []string{"email", "username"}, InsertOptions{}.UpdateColumnNames(table, []string{"email", "id", "username"}))
+	assert.Equal(t, []string{"username"}, InsertOptions{UpdateColumns: []string{"id", "username"}}.UpdateColumnNames(table, []string{"email", "id", "username"}))
+}
+
+func TestConflictError(t *testing.T) {
+	err := &ConflictError{TableName: "backer", ColumnNames: []string{"user_id", "project_id"}, Keys: [][]interface{}{{1, 2}}}
+	assert.EqualError(t, err, "1 rows conflict with existing rows of table backer on (user_id, project_id): (1, 2)")
+
+	for i := 0; i < 11; i++ {
+		err.Keys = append(err.Keys, []interface{}{i, i})
+	}
+	assert.Contains(t, err.Error(), "(8, 8), and 2 more")
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// Fixture describes an in-memory database in JSON.
+//
+//	{
+//	  "tables": [
+//	    {"name": "user", "primary_keys": ["id"], "columns": [{"name": "id", "data_type": "integer"}]},
+//	    {
+//	      "name": "project",
+//	      "primary_keys": ["id"],
+//	      "columns": [{"name": "id"}, {"name": "user_id", "nullable": true}],
+//	      "foreign_keys": [{"name": "project_user_id_fkey", "column_name": "user_id", "referenced_table_name": "user", "referenced_column_name": "id"}]
+//	    }
+//	  ],
+//	  "rows": {"user": [{"id": 1}], "project": [{"id": 1, "user_id": 1}]}
+//	}
+type Fixture struct {
+	Tables []FixtureTable                      `json:"tables"`
+	Rows   map[string][]map[string]interface{} `json:"rows"`
+}
+
+// FixtureTable describes a table of a Fixture.
+type FixtureTable struct {
+	Name        string              `json:"name"`
+	Kind        dialect.TableKind   `json:"kind"`
+	PrimaryKeys []string            `json:"primary_keys"`
+	UniqueKeys  []FixtureUniqueKey  `json:"unique_keys"`
+	Columns     []FixtureColumn     `json:"columns"`
+	ForeignKeys []FixtureForeignKey `json:"foreign_keys"`
+	// Sequences lists the columns owning a sequence, sequences are named <table>_<column>_seq.
+	Sequences []string `json:"sequences"`
+}
+
+// FixtureUniqueKey describes a unique key of a FixtureTable.
+type FixtureUniqueKey struct {
+	Name        string   `json:"name"`
+	ColumnNames []string `json:"column_names"`
+}
+
+// FixtureColumn describes a column of a FixtureTable.
+type FixtureColumn struct {
+	Name     string `json:"name"`
+	DataType string `json:"data_type"`
+	Nullable bool   `json:"nullable"`
+}
+
+// FixtureForeignKey describes a foreign key of a FixtureTable.
+//
+// Multi-column foreign keys are declared with column_names and referenced_column_names
+// instead of column_name and referenced_column_name.
+type FixtureForeignKey struct {
+	Name                  string   `json:"name"`
+	ColumnName            string   `json:"column_name"`
+	ColumnNames           []string `json:"column_names"`
+	ReferencedTableName   string   `json:"referenced_table_name"`
+	ReferencedColumnName  string   `json:"referenced_column_name"`
+	ReferencedColumnNames []string `json:"referenced_column_names"`
+}
+
+// References returns the ordered column pairs of the foreign key.
+func (f FixtureForeignKey) References() (dialect.ColumnReferences, error) {
+	columnNames, referencedColumnNames := f.ColumnNames, f.ReferencedColumnNames
+	if f.ColumnName != "" {
+		columnNames = append([]string{f.ColumnName}, columnNames...)
+	}
+	if f.ReferencedColumnName != "" {
+		referencedColumnNames = append([]string{f.ReferencedColumnName}, referencedColumnNames...)
+	}
+
+	if len(columnNames) == 0 || len(columnNames) != len(referencedColumnNames) {
+		return nil, fmt.Errorf("foreign key %s has %d columns for %d referenced columns",
+			f.Name, len(columnNames), len(referencedColumnNames))
+	}
+
+	references := make(dialect.ColumnReferences, len(columnNames))
+	for i := range columnNames {
+		references[i] = dialect.ColumnReference{
+			ColumnName:           columnNames[i],
+			ReferencedColumnName: referencedColumnNames[i],
+		}
+	}
+
+	return references, nil
+}
+
+// LoadFixture loads a Fixture from a JSON file.
+func LoadFixture(path string) (Fixture, error) {
+	var fixture Fixture
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fixture, err
+	}
+
+	if err := json.Unmarshal(content, &fixture); err != nil {
+		return fixture, err
+	}
+
+	return fixture, nil
+}
+
+// Dialect returns a MemoryDialect populated with the fixture tables and rows.
+func (f Fixture) Dialect() (*MemoryDialect, error) {
+	tables := make(dialect.Tables, len(f.Tables))
+	for i, table := range f.Tables {
+		tables[i] = dialect.Table{
+			Name:        table.Name,
+			Kind:        table.Kind,
+			PrimaryKeys: make([]dialect.PrimaryKey, len(table.PrimaryKeys)),
+			Columns:     make(dialect.Columns, len(table.Columns)),
+			ForeignKeys: make(dialect.ForeignKeys, len(table.ForeignKeys)),
+		}
+
+		for j, column := range table.Columns {
+			tables[i].Columns[j] = dialect.Column{
+				Name:     column.Name,
+				DataType: column.DataType,
+				Nullable: column.Nullable,
+				Position: int64(j + 1),
+			}
+		}
+
+		for j, name := range table.PrimaryKeys {
+			tables[i].PrimaryKeys[j] = dialect.PrimaryKey{
+				Name:     name,
+				DataType: tables[i].Columns.Get(name).DataType,
+			}
+		}
+
+		for _, uniqueKey := range table.UniqueKeys {
+			tables[i].UniqueKeys = append(tables[i].UniqueKeys, dialect.UniqueKey{
+				Name:        uniqueKey.Name,
+				ColumnNames: uniqueKey.ColumnNames,
+			})
+		}
+
+		for _, columnName := range table.Sequences {
+			tables[i].Sequences = append(tables[i].Sequences, dialect.Sequence{
+				Name:       fmt.Sprintf("%s_%s_seq", table.Name, columnName),
+				ColumnName: columnName,
+			})
+		}
+
+		for j, foreignKey := range table.ForeignKeys {
+			references, err := foreignKey.References()
+			if err != nil {
+				return nil, fmt.Errorf("unable to declare table %s: %w", table.Name, err)
+			}
+
+			tables[i].ForeignKeys[j] = dialect.ForeignKey{
+				Name:                foreignKey.Name,
+				Columns:             references,
+				ReferencedTableName: foreignKey.ReferencedTableName,
+			}
+		}
+	}
+
+	d := New(tables)
+
+	for i := range d.tables {
+		rows, ok := f.Rows[d.tables[i].Name]
+		if !ok {
+			continue
+		}
+
+		// Rows of views are declared as they would be selected.
+		if !d.tables[i].Insertable() {
+			d.rows[d.tables[i].Name] = copyRows(rows)
+			continue
+		}
+
+		if err := d.BulkInsert(context.Background(), d.tables[i], rows, dialect.InsertOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	d.inserts = nil
+
+	return d, nil
+}
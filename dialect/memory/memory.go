@@ -0,0 +1,776 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// defaultSchema is the schema of tables which are not qualified.
+const defaultSchema = "public"
+
+func init() {
+	dialect.Register("memory", NewMemoryDialect, "memory")
+}
+
+// NewMemoryDialect initializes a new MemoryDialect instance from a JSON fixture,
+// the DSN is the fixture path prefixed by memory:// (e.g. memory://testdata/fixture.json).
+// An empty path initializes an empty database.
+func NewMemoryDialect(ctx context.Context, dsn string) (dialect.Dialect, error) {
+	path := strings.TrimPrefix(dsn, "memory://")
+	if path == "" {
+		return New(nil), nil
+	}
+
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load fixture %s: %w", path, err)
+	}
+
+	return fixture.Dialect()
+}
+
+// New returns a MemoryDialect with tables declared in Go. Reference keys are computed
+// from foreign keys.
+func New(tables dialect.Tables) *MemoryDialect {
+	return &MemoryDialect{
+		tables:    linkTables(tables),
+		rows:      make(map[string][]map[string]interface{}),
+		sequences: make(map[string]int64),
+	}
+}
+
+// Query is a query executed by MemoryDialect.ResultSet.
+type Query struct {
+	Query string
+	Args  []interface{}
+}
+
+// MemoryDialect is an in-memory dialect which makes extraction and loading deterministic in tests.
+type MemoryDialect struct {
+	mu        sync.Mutex
+	tables    dialect.Tables
+	rows      map[string][]map[string]interface{}
+	queries   []Query
+	inserts   []string
+	updates   []string
+	deletes   []string
+	refreshes []string
+	// introspections counts the calls to Tables.
+	introspections int
+	// loads are the options passed to BeginLoad, snapshot holds the rows of an atomic or deferred load.
+	loads    []dialect.LoadOptions
+	snapshot map[string][]map[string]interface{}
+	// mode is the mode of the current load, foreign keys are checked in ordered mode.
+	mode dialect.LoadMode
+	// autoMode is the mode selected by the auto mode, see SetAutoMode.
+	autoMode dialect.LoadMode
+	// sequences are the last values allocated by AllocateKeys by sequence name.
+	sequences map[string]int64
+	// snapshots are the workers passed to BeginSnapshot.
+	snapshots []int
+}
+
+// Close closes a connection.
+func (d *MemoryDialect) Close(ctx context.Context) error {
+	return nil
+}
+
+// Rows returns a copy of the rows stored in a table.
+func (d *MemoryDialect) Rows(tableName string) []map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return copyRows(d.rows[tableName])
+}
+
+// Queries returns the queries executed by ResultSet.
+func (d *MemoryDialect) Queries() []Query {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Query(nil), d.queries...)
+}
+
+// Inserts returns the table names passed to BulkInsert in call order.
+func (d *MemoryDialect) Inserts() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.inserts...)
+}
+
+// Loads returns the options passed to BeginLoad in call order.
+func (d *MemoryDialect) Loads() []dialect.LoadOptions {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]dialect.LoadOptions(nil), d.loads...)
+}
+
+// SetAutoMode sets the mode selected by the auto mode, disable_triggers by default.
+func (d *MemoryDialect) SetAutoMode(mode dialect.LoadMode) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.autoMode = mode
+}
+
+// BeginLoad records the options of a load, the auto mode selects disable_triggers unless
+// another mode is set by SetAutoMode.
+// Rows are restored by EndLoad when an atomic or deferred load fails and foreign keys are checked by
+// BulkInsert in ordered mode.
+func (d *MemoryDialect) BeginLoad(ctx context.Context, opts dialect.LoadOptions) (dialect.LoadMode, error) {
+	if err := opts.Mode.Validate(); err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.loads = append(d.loads, opts)
+
+	mode := opts.Mode
+	if mode == "" || mode == dialect.LoadModeAuto {
+		mode = d.autoMode
+		if mode == "" {
+			mode = dialect.LoadModeDisableTriggers
+		}
+	}
+
+	if opts.Atomic || mode == dialect.LoadModeDeferred {
+		d.snapshot = make(map[string][]map[string]interface{}, len(d.rows))
+		for tableName, rows := range d.rows {
+			d.snapshot[tableName] = copyRows(rows)
+		}
+	}
+	d.mode = mode
+
+	return mode, nil
+}
+
+// EndLoad ends a load, rows of an atomic or deferred load are restored when it failed.
+func (d *MemoryDialect) EndLoad(ctx context.Context, err error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err != nil && d.snapshot != nil {
+		d.rows = d.snapshot
+	}
+	d.snapshot, d.mode = nil, ""
+
+	return nil
+}
+
+// Updates returns the table names passed to BulkUpdate in call order.
+func (d *MemoryDialect) Updates() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.updates...)
+}
+
+// Deletes returns the table names passed to BulkDelete in call order.
+func (d *MemoryDialect) Deletes() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.deletes...)
+}
+
+// Refreshes returns the materialized view names passed to RefreshMaterializedView in call order.
+func (d *MemoryDialect) Refreshes() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]string(nil), d.refreshes...)
+}
+
+// RefreshMaterializedView records the refresh of a materialized view, rows are left untouched.
+func (d *MemoryDialect) RefreshMaterializedView(ctx context.Context, table dialect.Table) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if table.Kind != dialect.TableKindMaterializedView {
+		return fmt.Errorf("unable to refresh %s: table is not a materialized view", table.Name)
+	}
+
+	d.refreshes = append(d.refreshes, table.Name)
+
+	return nil
+}
+
+// ResultSet executes a query and converts Rows in map[string]interface{}.
+//
+// Only "SELECT * FROM table [WHERE conditions]" queries are supported, conditions are
+// comparisons (=, <>, IN, = ANY, IS [NOT] NULL) combined with AND, OR and parentheses.
+func (d *MemoryDialect) ResultSet(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	stmt, err := parseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse query %s: %w", query, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.queries = append(d.queries, Query{Query: query, Args: args})
+
+	if d.tables.Get(stmt.tableName).Name == "" {
+		return nil, fmt.Errorf("unable to retrieve results : table %s does not exist", stmt.tableName)
+	}
+
+	results := make([]map[string]interface{}, 0)
+	for _, row := range d.rows[stmt.tableName] {
+		ok, err := stmt.match(row, args)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve results : %w", err)
+		}
+
+		if ok {
+			results = append(results, copyRow(row))
+		}
+	}
+
+	return results, nil
+}
+
+// BulkInsert inserts multiple data, conflicting rows are handled with the conflict strategy of the options.
+func (d *MemoryDialect) BulkInsert(ctx context.Context, table dialect.Table, data []map[string]interface{}, opts dialect.InsertOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.tables.Get(table.Name).Name == "" {
+		return fmt.Errorf("unable to insert to %s: table does not exist", table.Name)
+	}
+
+	if !table.Insertable() {
+		return fmt.Errorf("unable to insert to %s: %s is not insertable", table.Name, table.Kind)
+	}
+
+	if err := opts.Conflict.Validate(); err != nil {
+		return fmt.Errorf("unable to insert to %s: %w", table.Name, err)
+	}
+
+	keyColumnNames := table.KeyColumnNames()
+	if opts.ConflictStrategy() == dialect.ConflictFail {
+		keys := make([][]interface{}, 0)
+		for i := range data {
+			if d.find(table, data[i], keyColumnNames) >= 0 {
+				keys = append(keys, rowValues(data[i], keyColumnNames))
+			}
+		}
+
+		if len(keys) > 0 {
+			return &dialect.ConflictError{TableName: table.Name, ColumnNames: keyColumnNames, Keys: keys}
+		}
+	}
+
+	if d.mode == dialect.LoadModeOrdered {
+		for i := range data {
+			if err := d.checkForeignKeys(table, data[i]); err != nil {
+				return &dialect.RowError{TableName: table.Name, Index: i, Row: data[i], Err: err}
+			}
+		}
+	}
+
+	d.inserts = append(d.inserts, table.Name)
+
+	for i := range data {
+		switch opts.ConflictStrategy() {
+		case dialect.ConflictNothing:
+			if d.conflicts(table, data[i]) {
+				continue
+			}
+		case dialect.ConflictSkipDuplicates:
+			if d.duplicates(table, data[i]) {
+				continue
+			}
+		case dialect.ConflictUpdate:
+			if idx := d.find(table, data[i], keyColumnNames); idx >= 0 {
+				existing := d.rows[table.Name][idx]
+				for _, name := range opts.UpdateColumnNames(table, columnNames(data[i])) {
+					existing[name] = data[i][name]
+				}
+				continue
+			}
+		case dialect.ConflictReplace:
+			replaced := false
+			for idx := d.find(table, data[i], keyColumnNames); idx >= 0; idx = d.find(table, data[i], keyColumnNames) {
+				rows := d.rows[table.Name]
+				d.rows[table.Name] = append(rows[:idx:idx], rows[idx+1:]...)
+				replaced = true
+			}
+
+			if replaced {
+				d.rows[table.Name] = append(d.rows[table.Name], copyRow(data[i]))
+				continue
+			}
+		}
+
+		d.rows[table.Name] = append(d.rows[table.Name], copyRow(data[i]))
+
+		if opts.Inserted != nil && len(keyColumnNames) > 0 {
+			opts.Inserted(rowValues(data[i], keyColumnNames))
+		}
+	}
+
+	return nil
+}
+
+// BulkDelete deletes the rows with the given keys, keys of rows which do not exist are ignored.
+func (d *MemoryDialect) BulkDelete(ctx context.Context, table dialect.Table, keys [][]interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keyColumnNames := table.KeyColumnNames()
+	for i := range keys {
+		row := make(map[string]interface{}, len(keyColumnNames))
+		for j := range keyColumnNames {
+			row[keyColumnNames[j]] = keys[i][j]
+		}
+
+		if idx := d.find(table, row, keyColumnNames); idx >= 0 {
+			rows := d.rows[table.Name]
+			d.rows[table.Name] = append(rows[:idx:idx], rows[idx+1:]...)
+		}
+	}
+
+	d.deletes = append(d.deletes, table.Name)
+
+	return nil
+}
+
+// BulkUpdate sets the columns of the rows with the same key, it fails when a row does not exist.
+func (d *MemoryDialect) BulkUpdate(ctx context.Context, table dialect.Table, data []map[string]interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keyColumnNames := table.KeyColumnNames()
+	for i := range data {
+		idx := d.find(table, data[i], keyColumnNames)
+		if idx < 0 {
+			return fmt.Errorf("unable to update %v of %s: row does not exist", rowValues(data[i], keyColumnNames), table.Name)
+		}
+
+		if d.mode == dialect.LoadModeOrdered {
+			if err := d.checkForeignKeys(table, data[i]); err != nil {
+				return fmt.Errorf("unable to update %s: %w", table.Name, err)
+			}
+		}
+
+		for name, value := range data[i] {
+			d.rows[table.Name][idx][name] = value
+		}
+	}
+
+	d.updates = append(d.updates, table.Name)
+
+	return nil
+}
+
+// Snapshots returns the workers passed to BeginSnapshot in call order.
+func (d *MemoryDialect) Snapshots() []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]int(nil), d.snapshots...)
+}
+
+// BeginSnapshot records the number of workers, every reader is granted since ResultSet reads
+// rows under a lock.
+func (d *MemoryDialect) BeginSnapshot(ctx context.Context, workers int) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.snapshots = append(d.snapshots, workers)
+
+	return workers, nil
+}
+
+// EndSnapshot does nothing.
+func (d *MemoryDialect) EndSnapshot(ctx context.Context) error {
+	return nil
+}
+
+// BindsArrays returns true since "= ANY" conditions match slices.
+func (d *MemoryDialect) BindsArrays() bool {
+	return true
+}
+
+// AllocateKeys returns count new values of the sequence owned by a column, values start after
+// the largest value of the column.
+func (d *MemoryDialect) AllocateKeys(ctx context.Context, table dialect.Table, columnName string, count int) ([]int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	sequence, ok := d.tables.Get(table.Name).Sequence(columnName)
+	if !ok {
+		return nil, fmt.Errorf("unable to allocate keys of %s: column %s owns no sequence", table.Name, columnName)
+	}
+
+	last := d.sequences[sequence.Name]
+	for _, row := range d.rows[table.Name] {
+		if value, ok := toFloat64(row[columnName]); ok && int64(value) > last {
+			last = int64(value)
+		}
+	}
+
+	keys := make([]int64, count)
+	for i := range keys {
+		last++
+		keys[i] = last
+	}
+	d.sequences[sequence.Name] = last
+
+	return keys, nil
+}
+
+// DanglingReferences returns the number of rows of a table referencing rows which do not exist through
+// a foreign key, and at most limit of these rows.
+func (d *MemoryDialect) DanglingReferences(ctx context.Context, table dialect.Table, foreignKey dialect.ForeignKey, limit int) (int64, []map[string]interface{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		count int64
+		rows  = make([]map[string]interface{}, 0)
+	)
+	for _, row := range d.rows[table.Name] {
+		if !d.dangling(foreignKey, row) {
+			continue
+		}
+
+		count++
+		if len(rows) < limit {
+			rows = append(rows, copyRow(row))
+		}
+	}
+
+	return count, rows, nil
+}
+
+// ReferenceKeys returns the "Referenced by" constraints of a table.
+func (d *MemoryDialect) ReferenceKeys(ctx context.Context, tableName string) (dialect.ReferenceKeys, error) {
+	table, err := d.Table(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.ReferenceKeys, nil
+}
+
+// ForeignKeys returns the foreign keys of a table.
+func (d *MemoryDialect) ForeignKeys(ctx context.Context, tableName string) (dialect.ForeignKeys, error) {
+	table, err := d.Table(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	return table.ForeignKeys, nil
+}
+
+// PrimaryKeyConstraint returns the primary key constraint of a table.
+func (d *MemoryDialect) PrimaryKeyConstraint(ctx context.Context, tableName string) (string, error) {
+	table, err := d.Table(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+
+	if len(table.PrimaryKeys) == 0 {
+		return "", fmt.Errorf("table %s has no primary key", tableName)
+	}
+
+	return tableName + "_pkey", nil
+}
+
+// Columns returns sorted columns with types of a table.
+func (d *MemoryDialect) Columns(ctx context.Context, tableName string) ([]dialect.Column, error) {
+	if tableName != "" {
+		table, err := d.Table(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		return table.Columns, nil
+	}
+
+	columns := make([]dialect.Column, 0)
+	for i := range d.tables {
+		columns = append(columns, d.tables[i].Columns...)
+	}
+
+	return columns, nil
+}
+
+// Table returns a table with its reference keys, foreign keys, columns and primary keys.
+func (d *MemoryDialect) Table(ctx context.Context, tableName string) (dialect.Table, error) {
+	table := d.tables.Get(tableName)
+	if table.Name == "" {
+		return table, fmt.Errorf("table %s does not exist", tableName)
+	}
+
+	return table, nil
+}
+
+// Tables returns all the tables from the given schemas, the public schema is used by default.
+func (d *MemoryDialect) Tables(ctx context.Context, schemas ...string) (dialect.Tables, error) {
+	d.mu.Lock()
+	d.introspections++
+	d.mu.Unlock()
+
+	return d.tablesOf(schemas...), nil
+}
+
+// Introspections returns the number of calls to Tables.
+func (d *MemoryDialect) Introspections() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.introspections
+}
+
+// Identity identifies the in-memory database.
+func (d *MemoryDialect) Identity() string {
+	return "memory"
+}
+
+// SchemaFingerprint returns a hash of the tables of the given schemas.
+func (d *MemoryDialect) SchemaFingerprint(ctx context.Context, schemas ...string) (string, error) {
+	content, err := json.Marshal(d.tablesOf(schemas...))
+	if err != nil {
+		return "", fmt.Errorf("unable to compute schema fingerprint: %w", err)
+	}
+
+	hash := sha256.Sum256(content)
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func (d *MemoryDialect) tablesOf(schemas ...string) dialect.Tables {
+	if len(schemas) == 0 {
+		schemas = []string{defaultSchema}
+	}
+
+	tables := make(dialect.Tables, 0, len(d.tables))
+	for i := range d.tables {
+		for _, schema := range schemas {
+			if schema == defaultSchema {
+				schema = ""
+			}
+
+			if d.tables[i].Schema == schema {
+				tables = append(tables, d.tables[i])
+			}
+		}
+	}
+
+	return tables
+}
+
+// conflicts returns true when a row has the same primary key, or the same values on a unique key
+// when the table has no primary key.
+func (d *MemoryDialect) conflicts(table dialect.Table, row map[string]interface{}) bool {
+	if len(table.PrimaryKeys) > 0 {
+		return d.exists(table, row, table.PrimaryKeyColumnNames())
+	}
+
+	for i := range table.UniqueKeys {
+		if d.exists(table, row, table.UniqueKeys[i].ColumnNames) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// duplicates returns true when a row is identical to an existing row.
+func (d *MemoryDialect) duplicates(table dialect.Table, row map[string]interface{}) bool {
+	for _, existing := range d.rows[table.Name] {
+		if len(existing) != len(row) {
+			continue
+		}
+
+		found := true
+		for name, value := range row {
+			if _, ok := existing[name]; !ok || !equal(existing[name], value) {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exists returns true when a row has the same non NULL values on the given columns as an existing row.
+func (d *MemoryDialect) exists(table dialect.Table, row map[string]interface{}, columnNames []string) bool {
+	return d.find(table, row, columnNames) >= 0
+}
+
+// checkForeignKeys returns an error when a row references a row which does not exist,
+// foreign keys with a NULL column or whose columns are not set are not checked.
+func (d *MemoryDialect) checkForeignKeys(table dialect.Table, row map[string]interface{}) error {
+	for _, foreignKey := range table.ForeignKeys {
+		if d.dangling(foreignKey, row) {
+			return fmt.Errorf("%v references a row of %s which does not exist",
+				rowValues(row, foreignKey.Columns.ColumnNames()), foreignKey.ReferencedTableName)
+		}
+	}
+
+	return nil
+}
+
+// dangling returns true when a row references a row which does not exist through a foreign key,
+// foreign keys with a NULL column or whose columns are not set reference no row.
+func (d *MemoryDialect) dangling(foreignKey dialect.ForeignKey, row map[string]interface{}) bool {
+	referenced := make(map[string]interface{}, len(foreignKey.Columns))
+	for _, reference := range foreignKey.Columns {
+		if row[reference.ColumnName] != nil {
+			referenced[reference.ReferencedColumnName] = row[reference.ColumnName]
+		}
+	}
+
+	if len(referenced) < len(foreignKey.Columns) {
+		return false
+	}
+
+	referencedTable := d.tables.Get(foreignKey.ReferencedTableName)
+
+	return d.find(referencedTable, referenced, foreignKey.Columns.ReferencedColumnNames()) < 0
+}
+
+// find returns the index of the existing row with the same non NULL values on the given columns,
+// -1 when there is none or when no column is given.
+func (d *MemoryDialect) find(table dialect.Table, row map[string]interface{}, columnNames []string) int {
+	if len(columnNames) == 0 {
+		return -1
+	}
+
+	for i, existing := range d.rows[table.Name] {
+		found := true
+		for _, name := range columnNames {
+			if row[name] == nil || !equal(existing[name], row[name]) {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func linkTables(tables dialect.Tables) dialect.Tables {
+	tables = append(dialect.Tables(nil), tables...)
+
+	for i := range tables {
+		tables[i].Schema, _ = dialect.SplitName(tables[i].Name)
+		if tables[i].Kind == "" {
+			tables[i].Kind = dialect.TableKindTable
+		}
+		tables[i].ReferenceKeys = make(dialect.ReferenceKeys, 0)
+		for j := range tables[i].PrimaryKeys {
+			tables[i].PrimaryKeys[j].TableName = tables[i].Name
+		}
+		for j := range tables[i].Columns {
+			tables[i].Columns[j].TableName = tables[i].Name
+		}
+	}
+
+	for i := range tables {
+		for j := range tables[i].ForeignKeys {
+			foreignKey := tables[i].ForeignKeys[j]
+			for k := range tables {
+				if tables[k].Name == foreignKey.ReferencedTableName {
+					tables[k].ReferenceKeys = append(tables[k].ReferenceKeys, dialect.ReferenceKey{
+						Name:      foreignKey.Name,
+						TableName: tables[i].Name,
+						Columns:   foreignKey.Columns,
+					})
+				}
+			}
+		}
+	}
+
+	tablesMap := make(map[string]dialect.Table, len(tables))
+	for i := range tables {
+		tablesMap[tables[i].Name] = tables[i]
+	}
+
+	for i := range tables {
+		for j := range tables[i].ReferenceKeys {
+			tables[i].ReferenceKeys[j].Table = tablesMap[tables[i].ReferenceKeys[j].TableName]
+		}
+
+		for j := range tables[i].ForeignKeys {
+			tables[i].ForeignKeys[j].ReferencedTable = tablesMap[tables[i].ForeignKeys[j].ReferencedTableName]
+		}
+	}
+
+	return tables
+}
+
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(row))
+	for k, v := range row {
+		result[k] = v
+	}
+
+	return result
+}
+
+// columnNames returns the sorted columns of a row.
+func columnNames(row map[string]interface{}) []string {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func rowValues(row map[string]interface{}, columnNames []string) []interface{} {
+	values := make([]interface{}, len(columnNames))
+	for i := range columnNames {
+		values[i] = row[columnNames[i]]
+	}
+
+	return values
+}
+
+func copyRows(rows []map[string]interface{}) []map[string]interface{} {
+	results := make([]map[string]interface{}, len(rows))
+	for i := range rows {
+		results[i] = copyRow(rows[i])
+	}
+
+	return results
+}
+
+var (
+	_ dialect.Dialect                   = (*MemoryDialect)(nil)
+	_ dialect.MaterializedViewRefresher = (*MemoryDialect)(nil)
+	_ dialect.SchemaFingerprinter       = (*MemoryDialect)(nil)
+	_ dialect.LoadSession               = (*MemoryDialect)(nil)
+	_ dialect.Updater                   = (*MemoryDialect)(nil)
+	_ dialect.KeyAllocator              = (*MemoryDialect)(nil)
+	_ dialect.Unloader                  = (*MemoryDialect)(nil)
+	_ dialect.IntegrityChecker          = (*MemoryDialect)(nil)
+	_ dialect.ArrayBinder               = (*MemoryDialect)(nil)
+	_ dialect.Snapshotter               = (*MemoryDialect)(nil)
+)
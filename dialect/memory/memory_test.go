@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/dialect"
+)
+
+func newTestDialect(t *testing.T) *MemoryDialect {
+	d := New(dialect.Tables{
+		{
+			Name:        "user",
+			PrimaryKeys: []dialect.PrimaryKey{{Name: "id"}},
+			Columns:     dialect.Columns{{Name: "id"}, {Name: "username"}},
+		},
+		{
+			Name:        "project",
+			PrimaryKeys: []dialect.PrimaryKey{{Name: "id"}},
+			Columns:     dialect.Columns{{Name: "id"}, {Name: "user_id"}, {Name: "name"}},
+			ForeignKeys: dialect.ForeignKeys{
+				{
+					Name:                "project_user_id_fkey",
+					Columns:             dialect.ColumnReferences{{ColumnName: "user_id", ReferencedColumnName: "id"}},
+					ReferencedTableName: "user",
+				},
+			},
+		},
+	})
+
+	ctx := context.Background()
+	require.NoError(t, d.BulkInsert(ctx, d.tables.Get("user"), []map[string]interface{}{
+		{"id": float64(1), "username": "thoas"},
+		{"id": float64(2), "username": "ulule"},
+	}, dialect.InsertOptions{}))
+	require.NoError(t, d.BulkInsert(ctx, d.tables.Get("project"), []map[string]interface{}{
+		{"id": float64(1), "user_id": float64(1), "name": "mover"},
+		{"id": float64(2), "user_id": float64(1), "name": "loukoum"},
+		{"id": float64(3), "user_id": nil, "name": "orphan"},
+	}, dialect.InsertOptions{}))
+
+	return d
+}
+
+func TestTables(t *testing.T) {
+	d := newTestDialect(t)
+
+	tables, err := d.Tables(context.Background())
+	require.NoError(t, err)
+
+	user := tables.Get("user")
+	require.Len(t, user.ReferenceKeys, 1)
+	assert.Equal(t, "project_user_id_fkey", user.ReferenceKeys[0].Name)
+	assert.Equal(t, "project", user.ReferenceKeys[0].Table.Name)
+	assert.Equal(t, []string{"user_id"}, user.ReferenceKeys[0].Columns.ColumnNames())
+
+	project := tables.Get("project")
+	assert.Equal(t, "user", project.ForeignKeys[0].ReferencedTable.Name)
+	assert.Equal(t, "project", project.PrimaryKeys[0].TableName)
+}
+
+func TestResultSet(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	tests := []struct {
+		query string
+		args  []interface{}
+		ids   []float64
+	}{
+		{`SELECT * FROM project`, nil, []float64{1, 2, 3}},
+		{`select * from "project" where ("user_id" = $1)`, []interface{}{int64(1)}, []float64{1, 2}},
+		{`SELECT * FROM project WHERE name = 'mover'`, nil, []float64{1}},
+		{`SELECT * FROM project WHERE (("user_id" = $1) AND ("name" <> $2))`, []interface{}{1, "mover"}, []float64{2}},
+		{`SELECT * FROM project WHERE id IN ($1, $2) OR user_id IS NULL`, []interface{}{1, 2}, []float64{1, 2, 3}},
+		{`SELECT * FROM project WHERE id = ANY($1)`, []interface{}{[]int{2, 3}}, []float64{2, 3}},
+		{`SELECT * FROM project WHERE user_id IS NOT NULL AND id = 2`, nil, []float64{2}},
+	}
+
+	for _, tt := range tests {
+		results, err := d.ResultSet(ctx, tt.query, tt.args...)
+		require.NoError(t, err, tt.query)
+
+		ids := make([]float64, len(results))
+		for i := range results {
+			ids[i] = results[i]["id"].(float64)
+		}
+		assert.Equal(t, tt.ids, ids, tt.query)
+	}
+
+	assert.Len(t, d.Queries(), len(tests))
+
+	_, err := d.ResultSet(ctx, `SELECT id FROM project`)
+	assert.Error(t, err)
+
+	_, err = d.ResultSet(ctx, `SELECT * FROM unknown`)
+	assert.Error(t, err)
+}
+
+func TestBulkInsert(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	require.NoError(t, d.BulkInsert(ctx, d.tables.Get("user"), []map[string]interface{}{
+		{"id": int64(1), "username": "conflict"},
+		{"id": int64(3), "username": "mover"},
+	}, dialect.InsertOptions{}))
+
+	rows := d.Rows("user")
+	require.Len(t, rows, 3)
+	assert.Equal(t, "thoas", rows[0]["username"])
+	assert.Equal(t, "mover", rows[2]["username"])
+	assert.Equal(t, []string{"user", "project", "user"}, d.Inserts())
+}
+
+func TestBulkInsertConflictStrategies(t *testing.T) {
+	ctx := context.Background()
+	d := New(dialect.Tables{
+		{
+			Name:       "membership",
+			UniqueKeys: []dialect.UniqueKey{{Name: "membership_email_key", ColumnNames: []string{"email"}}},
+			Columns:    dialect.Columns{{Name: "email"}, {Name: "role"}},
+		},
+	})
+	table := d.tables.Get("membership")
+
+	rows := []map[string]interface{}{
+		{"email": "florent@ulule.com", "role": "admin"},
+		{"email": nil, "role": "guest"},
+	}
+	require.NoError(t, d.BulkInsert(ctx, table, rows, dialect.InsertOptions{}))
+
+	// Rows conflicting on the unique key are skipped, NULL values never conflict.
+	require.NoError(t, d.BulkInsert(ctx, table, []map[string]interface{}{
+		{"email": "florent@ulule.com", "role": "owner"},
+		{"email": nil, "role": "guest"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictNothing}))
+	assert.Len(t, d.Rows("membership"), 3)
+
+	require.NoError(t, d.BulkInsert(ctx, table, rows, dialect.InsertOptions{Conflict: dialect.ConflictSkipDuplicates}))
+	assert.Len(t, d.Rows("membership"), 3)
+
+	require.NoError(t, d.BulkInsert(ctx, table, rows, dialect.InsertOptions{Conflict: dialect.ConflictAppend}))
+	assert.Len(t, d.Rows("membership"), 5)
+
+	assert.Error(t, d.BulkInsert(ctx, table, rows, dialect.InsertOptions{Conflict: "unknown"}))
+}
+
+func TestBulkInsertUpdateReplaceFail(t *testing.T) {
+	ctx := context.Background()
+	d := New(dialect.Tables{
+		{
+			Name:        "user",
+			PrimaryKeys: []dialect.PrimaryKey{{Name: "id"}},
+			Columns:     dialect.Columns{{Name: "id"}, {Name: "username"}, {Name: "email"}},
+		},
+	})
+	table := d.tables.Get("user")
+
+	require.NoError(t, d.BulkInsert(ctx, table, []map[string]interface{}{
+		{"id": 1, "username": "thoas", "email": "florent@ulule.com"},
+		{"id": 2, "username": "ulule", "email": "ulule@ulule.com"},
+	}, dialect.InsertOptions{}))
+
+	// Only the selected columns of conflicting rows are updated.
+	require.NoError(t, d.BulkInsert(ctx, table, []map[string]interface{}{
+		{"id": 1, "username": "florent", "email": "thoas@ulule.com"},
+		{"id": 3, "username": "mover", "email": "mover@ulule.com"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictUpdate, UpdateColumns: []string{"username"}}))
+	rows := d.Rows("user")
+	require.Len(t, rows, 3)
+	assert.Equal(t, "florent", rows[0]["username"])
+	assert.Equal(t, "florent@ulule.com", rows[0]["email"])
+
+	// Replaced rows lose the columns which are not loaded.
+	require.NoError(t, d.BulkInsert(ctx, table, []map[string]interface{}{
+		{"id": 2, "username": "replaced"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictReplace}))
+	rows = d.Rows("user")
+	require.Len(t, rows, 3)
+	assert.Equal(t, map[string]interface{}{"id": 2, "username": "replaced"}, rows[2])
+
+	err := d.BulkInsert(ctx, table, []map[string]interface{}{
+		{"id": 3, "username": "conflict"},
+		{"id": 4, "username": "new"},
+		{"id": 1, "username": "conflict"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictFail})
+
+	var conflictErr *dialect.ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, []string{"id"}, conflictErr.ColumnNames)
+	assert.Equal(t, [][]interface{}{{3}, {1}}, conflictErr.Keys)
+	assert.Len(t, d.Rows("user"), 3)
+}
@@ -0,0 +1,526 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenParam
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.value, keyword)
+}
+
+func (t token) isSymbol(symbol string) bool {
+	return t.kind == tokenSymbol && t.value == symbol
+}
+
+func tokenize(query string) ([]token, error) {
+	var (
+		tokens = make([]token, 0)
+		runes  = []rune(query)
+	)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(runes); j++ {
+				if runes[j] == r {
+					if j+1 < len(runes) && runes[j+1] == r {
+						b.WriteRune(r)
+						j++
+						continue
+					}
+					break
+				}
+				b.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated quote at position %d", i)
+			}
+
+			kind := tokenString
+			if r == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, value: b.String()})
+			i = j + 1
+		case r == '$':
+			j := i + 1
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("invalid parameter at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokenParam, value: string(runes[i+1 : j])})
+			i = j
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: string(runes[i:j])})
+			i = j
+		case r == '<' || r == '!':
+			if i+1 < len(runes) && (runes[i+1] == '>' || runes[i+1] == '=') {
+				tokens = append(tokens, token{kind: tokenSymbol, value: "<>"})
+				i += 2
+				continue
+			}
+			return nil, fmt.Errorf("unsupported operator at position %d", i)
+		case strings.ContainsRune("()*,.=;", r):
+			tokens = append(tokens, token{kind: tokenSymbol, value: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unsupported character %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// operand is either a literal or a positional parameter.
+type operand struct {
+	param int
+	value interface{}
+}
+
+func (o operand) resolve(args []interface{}) (interface{}, error) {
+	if o.param == 0 {
+		return o.value, nil
+	}
+
+	if o.param > len(args) {
+		return nil, fmt.Errorf("missing argument $%d", o.param)
+	}
+
+	return args[o.param-1], nil
+}
+
+type condition interface {
+	match(row map[string]interface{}, args []interface{}) (bool, error)
+}
+
+type andCondition []condition
+
+func (c andCondition) match(row map[string]interface{}, args []interface{}) (bool, error) {
+	for i := range c {
+		ok, err := c[i].match(row, args)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+type orCondition []condition
+
+func (c orCondition) match(row map[string]interface{}, args []interface{}) (bool, error) {
+	for i := range c {
+		ok, err := c[i].match(row, args)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+type comparison struct {
+	column   string
+	operator string
+	operands []operand
+}
+
+func (c comparison) match(row map[string]interface{}, args []interface{}) (bool, error) {
+	value := row[c.column]
+
+	switch c.operator {
+	case "IS NULL":
+		return value == nil, nil
+	case "IS NOT NULL":
+		return value != nil, nil
+	}
+
+	values := make([]interface{}, 0, len(c.operands))
+	for i := range c.operands {
+		resolved, err := c.operands[i].resolve(args)
+		if err != nil {
+			return false, err
+		}
+
+		values = append(values, resolved)
+	}
+
+	if value == nil {
+		return false, nil
+	}
+
+	switch c.operator {
+	case "=":
+		return equal(value, values[0]), nil
+	case "<>":
+		return values[0] != nil && !equal(value, values[0]), nil
+	case "IN":
+		for i := range values {
+			if equal(value, values[i]) {
+				return true, nil
+			}
+		}
+	case "ANY":
+		rv := reflect.ValueOf(values[0])
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return false, fmt.Errorf("ANY expects a slice, got %T", values[0])
+		}
+
+		for i := 0; i < rv.Len(); i++ {
+			if equal(value, rv.Index(i).Interface()) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+type statement struct {
+	tableName string
+	where     condition
+}
+
+func (s statement) match(row map[string]interface{}, args []interface{}) (bool, error) {
+	if s.where == nil {
+		return true, nil
+	}
+
+	return s.where.match(row, args)
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if t := p.next(); !t.is(keyword) {
+		return fmt.Errorf("expected %s, got %q", keyword, t.value)
+	}
+
+	return nil
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if t := p.next(); !t.isSymbol(symbol) {
+		return fmt.Errorf("expected %s, got %q", symbol, t.value)
+	}
+
+	return nil
+}
+
+func (p *parser) identifier() (string, error) {
+	t := p.next()
+	if t.kind != tokenIdent && t.kind != tokenQuotedIdent {
+		return "", fmt.Errorf("expected identifier, got %q", t.value)
+	}
+
+	name := t.value
+	if p.peek().isSymbol(".") {
+		p.next()
+
+		part, err := p.identifier()
+		if err != nil {
+			return "", err
+		}
+
+		name += "." + part
+	}
+
+	return name, nil
+}
+
+func (p *parser) operand() (operand, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenParam:
+		index, err := strconv.Atoi(t.value)
+		if err != nil || index == 0 {
+			return operand{}, fmt.Errorf("invalid parameter $%s", t.value)
+		}
+
+		return operand{param: index}, nil
+	case tokenString:
+		return operand{value: t.value}, nil
+	case tokenNumber:
+		value, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return operand{}, err
+		}
+
+		return operand{value: value}, nil
+	case tokenIdent:
+		switch {
+		case t.is("true"):
+			return operand{value: true}, nil
+		case t.is("false"):
+			return operand{value: false}, nil
+		case t.is("null"):
+			return operand{value: nil}, nil
+		}
+	}
+
+	return operand{}, fmt.Errorf("unsupported operand %q", t.value)
+}
+
+func (p *parser) or() (condition, error) {
+	conditions := orCondition{}
+	for {
+		cond, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, cond)
+
+		if !p.peek().is("or") {
+			break
+		}
+		p.next()
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+
+	return conditions, nil
+}
+
+func (p *parser) and() (condition, error) {
+	conditions := andCondition{}
+	for {
+		cond, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, cond)
+
+		if !p.peek().is("and") {
+			break
+		}
+		p.next()
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+
+	return conditions, nil
+}
+
+func (p *parser) factor() (condition, error) {
+	if p.peek().isSymbol("(") {
+		p.next()
+
+		cond, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		return cond, p.expectSymbol(")")
+	}
+
+	column, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.next()
+	switch {
+	case t.isSymbol("=") && p.peek().is("any"):
+		p.next()
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+
+		value, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+
+		return comparison{column: column, operator: "ANY", operands: []operand{value}}, p.expectSymbol(")")
+	case t.isSymbol("=") || t.isSymbol("<>"):
+		value, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+
+		return comparison{column: column, operator: t.value, operands: []operand{value}}, nil
+	case t.is("in"):
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+
+		operands := make([]operand, 0)
+		for {
+			value, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+
+			operands = append(operands, value)
+
+			if !p.peek().isSymbol(",") {
+				break
+			}
+			p.next()
+		}
+
+		return comparison{column: column, operator: "IN", operands: operands}, p.expectSymbol(")")
+	case t.is("is"):
+		operator := "IS NULL"
+		if p.peek().is("not") {
+			p.next()
+			operator = "IS NOT NULL"
+		}
+
+		return comparison{column: column, operator: operator}, p.expectKeyword("null")
+	}
+
+	return nil, fmt.Errorf("unsupported operator %q", t.value)
+}
+
+func parseQuery(query string) (statement, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return statement{}, err
+	}
+
+	p := &parser{tokens: tokens}
+	if err := p.expectKeyword("select"); err != nil {
+		return statement{}, err
+	}
+
+	if err := p.expectSymbol("*"); err != nil {
+		return statement{}, errors.New("only SELECT * queries are supported")
+	}
+
+	if err := p.expectKeyword("from"); err != nil {
+		return statement{}, err
+	}
+
+	var stmt statement
+	stmt.tableName, err = p.identifier()
+	if err != nil {
+		return statement{}, err
+	}
+
+	if p.peek().is("where") {
+		p.next()
+
+		stmt.where, err = p.or()
+		if err != nil {
+			return statement{}, err
+		}
+	}
+
+	if p.peek().isSymbol(";") {
+		p.next()
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return statement{}, fmt.Errorf("unexpected %q", t.value)
+	}
+
+	return stmt, nil
+}
+
+// equal compares two values, numbers are compared regardless of their type
+// since rows loaded from JSON contain float64 values.
+func equal(a, b interface{}) bool {
+	fa, aok := toFloat64(a)
+	fb, bok := toFloat64(b)
+	if aok && bok {
+		return fa == fb
+	}
+
+	return reflect.DeepEqual(a, b) || fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), true
+	case int8:
+		return float64(value), true
+	case int16:
+		return float64(value), true
+	case int32:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case uint:
+		return float64(value), true
+	case uint8:
+		return float64(value), true
+	case uint16:
+		return float64(value), true
+	case uint32:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float32:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,756 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// tableKinds maps information_schema.tables.table_type to table kinds.
+var tableKinds = map[string]dialect.TableKind{
+	"BASE TABLE": dialect.TableKindTable,
+	"VIEW":       dialect.TableKindView,
+}
+
+func init() {
+	dialect.Register("mysql", NewMySQLDialect, "mysql")
+}
+
+// NewMySQLDialect initializes a new MySQLDialect instance.
+func NewMySQLDialect(ctx context.Context, dsn string) (dialect.Dialect, error) {
+	cfg, err := parseDSN(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse dsn %s: %w", dsn, err)
+	}
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to database with dsn %s: %w", dsn, err)
+	}
+
+	db := sql.OpenDB(connector)
+
+	var database sql.NullString
+	if err := db.QueryRowContext(ctx, "SELECT DATABASE()").Scan(&database); err != nil {
+		return nil, fmt.Errorf("unable to connect to database with dsn %s: %w", dsn, err)
+	}
+
+	return &MySQLDialect{
+		db:       db,
+		database: database.String,
+	}, nil
+}
+
+// MySQLDialect manages a connection with MySQL or MariaDB.
+//
+// MySQL schemas are databases, tables of the database selected by the DSN are not qualified.
+type MySQLDialect struct {
+	db       *sql.DB
+	database string
+}
+
+// Close closes a connection.
+func (d *MySQLDialect) Close(ctx context.Context) error {
+	return d.db.Close()
+}
+
+// ResultSet executes a query and converts Rows in map[string]interface{}.
+func (d *MySQLDialect) ResultSet(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	query, args = rewritePlaceholders(query, args)
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve results : %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		result, err := marshalRows(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve results : %w", err)
+	}
+
+	return results, nil
+}
+
+// DanglingReferences returns the number of rows of a table referencing rows which do not exist through
+// a foreign key, and at most limit of these rows.
+func (d *MySQLDialect) DanglingReferences(ctx context.Context, table dialect.Table, foreignKey dialect.ForeignKey, limit int) (int64, []map[string]interface{}, error) {
+	clause := dialect.DanglingReferencesClause(table, foreignKey, quoteIdentifier)
+
+	var count int64
+	if err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) "+clause).Scan(&count); err != nil {
+		return 0, nil, fmt.Errorf("unable to count dangling references of %s: %w", table.Name, err)
+	}
+
+	if count == 0 || limit == 0 {
+		return count, nil, nil
+	}
+
+	rows, err := d.ResultSet(ctx, fmt.Sprintf("SELECT c.* %s LIMIT %d", clause, limit))
+	if err != nil {
+		return 0, nil, fmt.Errorf("unable to retrieve dangling references of %s: %w", table.Name, err)
+	}
+
+	return count, rows, nil
+}
+
+// BulkInsert inserts multiple data a single database transaction. It disables foreign key checks to avoid
+// conflicts on foreign constraints.
+func (d *MySQLDialect) BulkInsert(ctx context.Context, table dialect.Table, data []map[string]interface{}, opts dialect.InsertOptions) error {
+	if !table.Insertable() {
+		return fmt.Errorf("unable to insert to %s: %s is not insertable", table.Name, table.Kind)
+	}
+
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve connection for table %s: %w", table.Name, err)
+	}
+	defer conn.Close()
+
+	if err := d.disableForeignKeyChecks(ctx, conn, func(ctx context.Context) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("unable to begin transaction on table %s: %w", table.Name, err)
+		}
+
+		if opts.ConflictStrategy() == dialect.ConflictFail {
+			if err := d.conflicts(ctx, tx, table, data); err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+		}
+
+		for i := range data {
+			if err := d.insert(ctx, tx, table, data[i], opts); err != nil {
+				_ = tx.Rollback()
+				return &dialect.RowError{TableName: table.Name, Index: i, Row: data[i], Err: err}
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("unable to commit transaction on table %s: %w", table.Name, err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := d.resetAutoIncrement(ctx, table); err != nil {
+		return fmt.Errorf("unable to reset auto increment on table %s: %w", table.Name, err)
+	}
+
+	return nil
+}
+
+// ReferenceKeys returns the "Referenced by" constraints of a table.
+func (d *MySQLDialect) ReferenceKeys(ctx context.Context, tableName string) (dialect.ReferenceKeys, error) {
+	query := `SELECT constraint_name, table_schema, table_name, column_name, referenced_column_name
+FROM information_schema.key_column_usage
+WHERE referenced_table_schema = ? AND referenced_table_name = ?
+ORDER BY table_schema, table_name, constraint_name, ordinal_position`
+
+	schema, name := d.splitName(tableName)
+	rows, err := d.db.QueryContext(ctx, query, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s reference keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	referenceKeys := make(dialect.ReferenceKeys, 0)
+	for rows.Next() {
+		var (
+			name            string
+			schema          string
+			table           string
+			columnReference dialect.ColumnReference
+		)
+		if err := rows.Scan(&name, &schema, &table, &columnReference.ColumnName, &columnReference.ReferencedColumnName); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s reference keys: %w", tableName, err)
+		}
+
+		table = d.qualifiedName(schema, table)
+		last := len(referenceKeys) - 1
+		if last >= 0 && referenceKeys[last].Name == name && referenceKeys[last].TableName == table {
+			referenceKeys[last].Columns = append(referenceKeys[last].Columns, columnReference)
+			continue
+		}
+
+		referenceKeys = append(referenceKeys, dialect.ReferenceKey{
+			Name:      name,
+			TableName: table,
+			Columns:   dialect.ColumnReferences{columnReference},
+		})
+	}
+
+	return referenceKeys, rows.Err()
+}
+
+// ForeignKeys returns the foreign keys of a table.
+func (d *MySQLDialect) ForeignKeys(ctx context.Context, tableName string) (dialect.ForeignKeys, error) {
+	query := `SELECT constraint_name, column_name, referenced_table_schema, referenced_table_name, referenced_column_name
+FROM information_schema.key_column_usage
+WHERE table_schema = ? AND table_name = ? AND referenced_table_name IS NOT NULL
+ORDER BY constraint_name, ordinal_position`
+
+	schema, name := d.splitName(tableName)
+	rows, err := d.db.QueryContext(ctx, query, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s foreign keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	foreignKeys := make(dialect.ForeignKeys, 0)
+	for rows.Next() {
+		var (
+			name                string
+			referencedSchema    string
+			referencedTableName string
+			columnReference     dialect.ColumnReference
+		)
+		if err := rows.Scan(&name, &columnReference.ColumnName, &referencedSchema, &referencedTableName, &columnReference.ReferencedColumnName); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s foreign keys: %w", tableName, err)
+		}
+
+		last := len(foreignKeys) - 1
+		if last >= 0 && foreignKeys[last].Name == name {
+			foreignKeys[last].Columns = append(foreignKeys[last].Columns, columnReference)
+			continue
+		}
+
+		foreignKeys = append(foreignKeys, dialect.ForeignKey{
+			Name:                name,
+			Columns:             dialect.ColumnReferences{columnReference},
+			ReferencedTableName: d.qualifiedName(referencedSchema, referencedTableName),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s foreign keys: %w", tableName, err)
+	}
+
+	for i := range foreignKeys {
+		foreignKey := &foreignKeys[i]
+		foreignKey.Definition = fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)",
+			strings.Join(foreignKey.Columns.ColumnNames(), ", "),
+			foreignKey.ReferencedTableName,
+			strings.Join(foreignKey.Columns.ReferencedColumnNames(), ", "))
+	}
+
+	return foreignKeys, nil
+}
+
+// PrimaryKeyConstraint returns the primary key constraint of a table.
+func (d *MySQLDialect) PrimaryKeyConstraint(ctx context.Context, tableName string) (string, error) {
+	query := `SELECT constraint_name
+FROM information_schema.table_constraints
+WHERE table_schema = ? AND table_name = ? AND constraint_type = 'PRIMARY KEY'`
+
+	schema, name := d.splitName(tableName)
+
+	var result string
+	if err := d.db.QueryRowContext(ctx, query, schema, name).Scan(&result); err != nil {
+		return "", fmt.Errorf("unable to retrieve table %s primary key constraint: %w", tableName, err)
+	}
+
+	return result, nil
+}
+
+// PrimaryKeys returns primary keys of a table.
+func (d *MySQLDialect) PrimaryKeys(ctx context.Context, tableName string) ([]dialect.PrimaryKey, error) {
+	query := `SELECT k.column_name, c.column_type
+FROM information_schema.key_column_usage k
+JOIN information_schema.columns c
+  ON c.table_schema = k.table_schema AND c.table_name = k.table_name AND c.column_name = k.column_name
+WHERE k.table_schema = ? AND k.table_name = ? AND k.constraint_name = 'PRIMARY'
+ORDER BY k.ordinal_position`
+
+	schema, name := d.splitName(tableName)
+	rows, err := d.db.QueryContext(ctx, query, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s primary keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	primaryKeys := make([]dialect.PrimaryKey, 0)
+	for rows.Next() {
+		primaryKey := dialect.PrimaryKey{TableName: tableName}
+		if err := rows.Scan(&primaryKey.Name, &primaryKey.DataType); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s primary keys: %w", tableName, err)
+		}
+
+		primaryKeys = append(primaryKeys, primaryKey)
+	}
+
+	return primaryKeys, rows.Err()
+}
+
+// UniqueKeys returns the unique indexes of a table which are not the primary key.
+func (d *MySQLDialect) UniqueKeys(ctx context.Context, tableName string) ([]dialect.UniqueKey, error) {
+	query := `SELECT index_name, column_name
+FROM information_schema.statistics
+WHERE table_schema = ? AND table_name = ? AND non_unique = 0 AND index_name <> 'PRIMARY' AND column_name IS NOT NULL
+ORDER BY index_name, seq_in_index`
+
+	schema, name := d.splitName(tableName)
+	rows, err := d.db.QueryContext(ctx, query, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s unique keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	uniqueKeys := make([]dialect.UniqueKey, 0)
+	for rows.Next() {
+		var name, columnName string
+		if err := rows.Scan(&name, &columnName); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s unique keys: %w", tableName, err)
+		}
+
+		last := len(uniqueKeys) - 1
+		if last >= 0 && uniqueKeys[last].Name == name {
+			uniqueKeys[last].ColumnNames = append(uniqueKeys[last].ColumnNames, columnName)
+			continue
+		}
+
+		uniqueKeys = append(uniqueKeys, dialect.UniqueKey{Name: name, ColumnNames: []string{columnName}})
+	}
+
+	return uniqueKeys, rows.Err()
+}
+
+// Columns returns sorted columns with types of a table.
+func (d *MySQLDialect) Columns(ctx context.Context, tableName string) ([]dialect.Column, error) {
+	query := `SELECT column_name, column_type, is_nullable = 'YES', table_schema, table_name, ordinal_position
+FROM information_schema.columns`
+	args := make([]interface{}, 0)
+
+	if tableName != "" {
+		schema, name := d.splitName(tableName)
+		query += " WHERE table_schema = ? AND table_name = ?"
+		args = append(args, schema, name)
+	} else {
+		query += " WHERE table_schema NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')"
+	}
+
+	query += " ORDER BY table_schema, table_name, ordinal_position"
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+	}
+	defer rows.Close()
+
+	columns := make([]dialect.Column, 0)
+	for rows.Next() {
+		var (
+			column dialect.Column
+			schema string
+		)
+		if err := rows.Scan(&column.Name, &column.DataType, &column.Nullable, &schema, &column.TableName, &column.Position); err != nil {
+			return nil, fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+		}
+		column.TableName = d.qualifiedName(schema, column.TableName)
+
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+// Table returns a table with its reference keys, foreign keys, columns and primary keys.
+func (d *MySQLDialect) Table(ctx context.Context, tableName string) (dialect.Table, error) {
+	columns, err := d.Columns(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	kind, err := d.tableKind(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	schema, _ := dialect.SplitName(tableName)
+	table := dialect.Table{
+		Name:    tableName,
+		Schema:  schema,
+		Kind:    kind,
+		Columns: columns,
+	}
+	table.ReferenceKeys, err = d.ReferenceKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	table.ForeignKeys, err = d.ForeignKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	table.PrimaryKeys, err = d.PrimaryKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	table.UniqueKeys, err = d.UniqueKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	return table, nil
+}
+
+// Tables returns all the tables from the given schemas (databases), the database selected
+// by the DSN is used by default.
+func (d *MySQLDialect) Tables(ctx context.Context, schemas ...string) (dialect.Tables, error) {
+	if len(schemas) == 0 {
+		schemas = []string{d.database}
+	}
+
+	args := make([]interface{}, len(schemas))
+	for i := range schemas {
+		args[i] = schemas[i]
+	}
+
+	query := fmt.Sprintf(`SELECT table_schema, table_name, table_type
+FROM information_schema.tables
+WHERE table_schema IN (%s) AND table_type IN ('BASE TABLE', 'VIEW')
+ORDER BY table_schema, table_name`, strings.TrimSuffix(strings.Repeat("?, ", len(schemas)), ", "))
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+	defer rows.Close()
+
+	var (
+		tableNames []string
+		kinds      = make(map[string]dialect.TableKind)
+	)
+	for rows.Next() {
+		var schema, tableName, tableType string
+		if err := rows.Scan(&schema, &tableName, &tableType); err != nil {
+			return nil, fmt.Errorf("unable to execute query %s: %w", query, err)
+		}
+
+		tableName = d.qualifiedName(schema, tableName)
+		tableNames = append(tableNames, tableName)
+		kinds[tableName] = tableKinds[tableType]
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+
+	columns, err := d.Columns(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve columns: %w", err)
+	}
+
+	sortedColumns := make(map[string]dialect.Columns)
+	for i := range columns {
+		tableName := columns[i].TableName
+		sortedColumns[tableName] = append(sortedColumns[tableName], columns[i])
+	}
+
+	tables := make(dialect.Tables, len(tableNames))
+	for i := range tableNames {
+		sort.Sort(sortedColumns[tableNames[i]])
+		schema, _ := dialect.SplitName(tableNames[i])
+		tables[i] = dialect.Table{
+			Name:    tableNames[i],
+			Schema:  schema,
+			Kind:    kinds[tableNames[i]],
+			Columns: sortedColumns[tableNames[i]],
+		}
+		tables[i].ReferenceKeys, err = d.ReferenceKeys(ctx, tableNames[i])
+		if err != nil {
+			return nil, err
+		}
+
+		tables[i].ForeignKeys, err = d.ForeignKeys(ctx, tableNames[i])
+		if err != nil {
+			return nil, err
+		}
+
+		tables[i].PrimaryKeys, err = d.PrimaryKeys(ctx, tableNames[i])
+		if err != nil {
+			return nil, err
+		}
+
+		tables[i].UniqueKeys, err = d.UniqueKeys(ctx, tableNames[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tablesMap := make(map[string]dialect.Table, len(tables))
+	for i := range tables {
+		tablesMap[tables[i].Name] = tables[i]
+	}
+
+	for i := range tables {
+		for j := range tables[i].ReferenceKeys {
+			tables[i].ReferenceKeys[j].Table = tablesMap[tables[i].ReferenceKeys[j].TableName]
+		}
+
+		for j := range tables[i].ForeignKeys {
+			tables[i].ForeignKeys[j].ReferencedTable = tablesMap[tables[i].ForeignKeys[j].ReferencedTableName]
+		}
+	}
+
+	return tables, nil
+}
+
+func (d *MySQLDialect) tableKind(ctx context.Context, tableName string) (dialect.TableKind, error) {
+	query := `SELECT table_type FROM information_schema.tables WHERE table_schema = ? AND table_name = ?`
+
+	schema, name := d.splitName(tableName)
+
+	var tableType string
+	if err := d.db.QueryRowContext(ctx, query, schema, name).Scan(&tableType); err != nil {
+		return "", fmt.Errorf("unable to retrieve table %s kind: %w", tableName, err)
+	}
+
+	return tableKinds[tableType], nil
+}
+
+func (d *MySQLDialect) insert(ctx context.Context, tx *sql.Tx, table dialect.Table, data map[string]interface{}, opts dialect.InsertOptions) error {
+	columns, args, err := valuesToArgs(table, data)
+	if err != nil {
+		return fmt.Errorf("unable to convert %v to arguments: %w", data, err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		quoteIdentifier(table.Name),
+		strings.Join(quoteIdentifiers(columns), ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+
+	switch opts.ConflictStrategy() {
+	case dialect.ConflictNothing:
+		primaryKeys := table.PrimaryKeyColumnNames()
+		if len(primaryKeys) == 0 {
+			// Without a primary key, rows conflicting on any unique index are skipped: assigning a column
+			// to itself leaves the existing row untouched.
+			query += fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", quoteIdentifier(columns[0]), quoteIdentifier(columns[0]))
+			break
+		}
+
+		// ON DUPLICATE KEY UPDATE would also skip rows conflicting on other unique indexes, rows are
+		// only skipped when their primary key exists so that other conflicts fail as with PostgreSQL.
+		keyArgs, ok := keyValues(primaryKeys, columns, args)
+		if !ok {
+			break
+		}
+
+		exists, err := d.exists(ctx, tx, table, primaryKeys, keyArgs)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return nil
+		}
+	case dialect.ConflictSkipDuplicates:
+		exists, err := d.exists(ctx, tx, table, columns, args)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return nil
+		}
+	case dialect.ConflictUpdate:
+		if len(table.KeyColumnNames()) == 0 {
+			break
+		}
+
+		// Updating a key column with its own value leaves the existing row untouched.
+		updates := opts.UpdateColumnNames(table, columns)
+		if len(updates) == 0 {
+			updates = table.KeyColumnNames()[:1]
+		}
+
+		// MySQL applies the update to rows conflicting on the primary key as well as on any unique index.
+		sets := make([]string, len(updates))
+		for i := range updates {
+			sets[i] = fmt.Sprintf("%s = VALUES(%s)", quoteIdentifier(updates[i]), quoteIdentifier(updates[i]))
+		}
+		query += " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	case dialect.ConflictReplace:
+		if err := d.delete(ctx, tx, table, columns, args); err != nil {
+			return err
+		}
+	case dialect.ConflictAppend, dialect.ConflictFail:
+	default:
+		return fmt.Errorf("unable to insert to %s: %w", table.Name, opts.Conflict.Validate())
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to insert %v+ to %s:%w", data, table.Name, err)
+	}
+
+	return nil
+}
+
+// delete deletes the row with the same key as the given values, rows with a NULL key conflict with no row.
+func (d *MySQLDialect) delete(ctx context.Context, tx *sql.Tx, table dialect.Table, columns []string, args []interface{}) error {
+	keyColumnNames := table.KeyColumnNames()
+	keyArgs, ok := keyValues(keyColumnNames, columns, args)
+	if !ok {
+		return nil
+	}
+
+	conditions := make([]string, len(keyColumnNames))
+	for i := range keyColumnNames {
+		conditions[i] = quoteIdentifier(keyColumnNames[i]) + " = ?"
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", quoteIdentifier(table.Name), strings.Join(conditions, " AND "))
+	if _, err := tx.ExecContext(ctx, query, keyArgs...); err != nil {
+		return fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+
+	return nil
+}
+
+// conflicts returns a ConflictError reporting the rows conflicting on the key of the table,
+// nil when no row conflicts.
+func (d *MySQLDialect) conflicts(ctx context.Context, tx *sql.Tx, table dialect.Table, data []map[string]interface{}) error {
+	keyColumnNames := table.KeyColumnNames()
+	keys := make([][]interface{}, 0)
+	for i := range data {
+		columns, args, err := valuesToArgs(table, data[i])
+		if err != nil {
+			return fmt.Errorf("unable to convert %v to arguments: %w", data[i], err)
+		}
+
+		keyArgs, ok := keyValues(keyColumnNames, columns, args)
+		if !ok {
+			continue
+		}
+
+		exists, err := d.exists(ctx, tx, table, keyColumnNames, keyArgs)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			keys = append(keys, keyArgs)
+		}
+	}
+
+	if len(keys) > 0 {
+		return &dialect.ConflictError{TableName: table.Name, ColumnNames: keyColumnNames, Keys: keys}
+	}
+
+	return nil
+}
+
+// exists returns true when a row of the table is identical to the given values, NULL values included.
+func (d *MySQLDialect) exists(ctx context.Context, tx *sql.Tx, table dialect.Table, columns []string, args []interface{}) (bool, error) {
+	conditions := make([]string, len(columns))
+	for i := range columns {
+		conditions[i] = quoteIdentifier(columns[i]) + " <=> ?"
+	}
+
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE %s LIMIT 1", quoteIdentifier(table.Name), strings.Join(conditions, " AND "))
+
+	var result int
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&result); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+
+	return true, nil
+}
+
+func (d *MySQLDialect) disableForeignKeyChecks(ctx context.Context, conn *sql.Conn, f func(ctx context.Context) error) error {
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return fmt.Errorf("unable to disable foreign key checks: %w", err)
+	}
+
+	err := f(ctx)
+
+	if _, cerr := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1"); cerr != nil && err == nil {
+		err = fmt.Errorf("unable to enable foreign key checks: %w", cerr)
+	}
+
+	return err
+}
+
+func (d *MySQLDialect) resetAutoIncrement(ctx context.Context, table dialect.Table) error {
+	query := `SELECT column_name
+FROM information_schema.columns
+WHERE table_schema = ? AND table_name = ? AND extra LIKE '%auto_increment%'`
+
+	schema, name := d.splitName(table.Name)
+
+	var columnName string
+	if err := d.db.QueryRowContext(ctx, query, schema, name).Scan(&columnName); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+
+		return err
+	}
+
+	var maxval sql.NullInt64
+	if err := d.db.QueryRowContext(ctx, fmt.Sprintf("SELECT MAX(%s) FROM %s",
+		quoteIdentifier(columnName), quoteIdentifier(table.Name))).Scan(&maxval); err != nil {
+		return err
+	}
+
+	if !maxval.Valid {
+		return nil
+	}
+
+	if _, err := d.db.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s AUTO_INCREMENT = %d",
+		quoteIdentifier(table.Name), maxval.Int64+1)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// splitName returns the database and the name of a table.
+func (d *MySQLDialect) splitName(tableName string) (string, string) {
+	schema, name := dialect.SplitName(tableName)
+	if schema == "" {
+		schema = d.database
+	}
+
+	return schema, name
+}
+
+// qualifiedName returns the name of a table qualified by its database unless it belongs to
+// the database selected by the DSN.
+func (d *MySQLDialect) qualifiedName(schema, name string) string {
+	if schema == d.database {
+		schema = ""
+	}
+
+	return dialect.QualifiedName(schema, name)
+}
+
+var (
+	_ dialect.Dialect          = (*MySQLDialect)(nil)
+	_ dialect.IntegrityChecker = (*MySQLDialect)(nil)
+)
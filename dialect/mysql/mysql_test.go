@@ -0,0 +1,185 @@
+//go:build integration
+
+package mysql
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// Integration tests run against the database of MOVER_MYSQL_DSN with: go test -tags integration ./dialect/mysql/
+// Their tables are dropped and created again.
+const testSchema = `
+DROP TABLE IF EXISTS project, membership, user;
+CREATE TABLE user (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	username VARCHAR(255) NOT NULL UNIQUE,
+	profile JSON
+);
+CREATE TABLE project (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	user_id INT NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES user(id)
+);
+CREATE TABLE membership (
+	email VARCHAR(255),
+	role VARCHAR(255) NOT NULL,
+	UNIQUE KEY membership_email_key (email)
+);
+`
+
+func newTestDialect(t *testing.T) *MySQLDialect {
+	dsn := os.Getenv("MOVER_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("MOVER_MYSQL_DSN is not set")
+	}
+
+	ctx := context.Background()
+
+	d, err := NewMySQLDialect(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, d.Close(ctx))
+	})
+
+	mysqlDialect := d.(*MySQLDialect)
+	for _, statement := range strings.Split(testSchema, ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+
+		_, err := mysqlDialect.db.ExecContext(ctx, statement)
+		require.NoError(t, err)
+	}
+
+	return mysqlDialect
+}
+
+func TestTables(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	tables, err := d.Tables(ctx)
+	require.NoError(t, err)
+
+	user := tables.Get("user")
+	assert.Equal(t, []string{"id"}, user.PrimaryKeyColumnNames())
+	require.Len(t, user.UniqueKeys, 1)
+	assert.Equal(t, []string{"username"}, user.UniqueKeys[0].ColumnNames)
+	require.Len(t, user.ReferenceKeys, 1)
+	assert.Equal(t, "project", user.ReferenceKeys[0].Table.Name)
+
+	project := tables.Get("project")
+	require.Len(t, project.ForeignKeys, 1)
+	assert.Equal(t, "user", project.ForeignKeys[0].ReferencedTable.Name)
+	assert.Equal(t, []string{"user_id"}, project.ForeignKeys[0].Columns.ColumnNames())
+
+	membership := tables.Get("membership")
+	assert.Empty(t, membership.PrimaryKeys)
+	assert.Equal(t, []string{"email"}, membership.KeyColumnNames())
+}
+
+func TestBulkInsert(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	project, err := d.Table(ctx, "project")
+	require.NoError(t, err)
+
+	// The referenced user does not exist yet, foreign keys must not be enforced.
+	require.NoError(t, d.BulkInsert(ctx, project, []map[string]interface{}{
+		{"id": float64(1), "name": "mover", "user_id": float64(1)},
+	}, dialect.InsertOptions{}))
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(1), "username": "thoas", "profile": map[string]interface{}{"lang": "fr"}},
+	}, dialect.InsertOptions{}))
+
+	results, err := d.ResultSet(ctx, `SELECT * FROM "user" WHERE ("id" = $1 AND "username" <> '$2')`, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "thoas", results[0]["username"])
+	assert.Equal(t, map[string]interface{}{"lang": "fr"}, results[0]["profile"])
+
+	// The auto increment is moved past the loaded rows.
+	_, err = d.db.ExecContext(ctx, `INSERT INTO user (username) VALUES ('ulule')`)
+	require.NoError(t, err)
+
+	results, err = d.ResultSet(ctx, `SELECT "id" FROM "user" WHERE "username" = $1`, "ulule")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(2), results[0]["id"])
+}
+
+func TestBulkInsertConflictNothing(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": 1, "username": "thoas"},
+	}, dialect.InsertOptions{}))
+
+	// Rows conflicting on the primary key are skipped.
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": 1, "username": "ulule"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictNothing}))
+
+	results, err := d.ResultSet(ctx, `SELECT * FROM "user"`)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "thoas", results[0]["username"])
+
+	// Rows conflicting on another unique index fail as with PostgreSQL.
+	err = d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": 2, "username": "thoas"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictNothing})
+
+	var rowErr *dialect.RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 0, rowErr.Index)
+
+	// Without a primary key, rows conflicting on a unique index are skipped.
+	membership, err := d.Table(ctx, "membership")
+	require.NoError(t, err)
+
+	require.NoError(t, d.BulkInsert(ctx, membership, []map[string]interface{}{
+		{"email": "florent@ulule.com", "role": "admin"},
+		{"email": "florent@ulule.com", "role": "owner"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictNothing}))
+
+	results, err = d.ResultSet(ctx, `SELECT * FROM "membership"`)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "admin", results[0]["role"])
+}
+
+func TestSQLMode(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	var sqlMode string
+	require.NoError(t, d.db.QueryRowContext(ctx, "SELECT @@SESSION.sql_mode").Scan(&sqlMode))
+	assert.Contains(t, strings.Split(sqlMode, ","), "ANSI_QUOTES")
+}
@@ -0,0 +1,552 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	lk "github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/builder"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// relation is a table, view or foreign table of pg_class.
+type relation struct {
+	OID    int64  `db:"oid"`
+	Schema string `db:"schema"`
+	Name   string `db:"name"`
+	Kind   string `db:"kind"`
+}
+
+// TableName returns the qualified name of the relation.
+func (r relation) TableName() string {
+	return qualifiedName(r.Schema, r.Name)
+}
+
+// relations returns the relations of the given schemas, partitions are loaded through
+// their partitioned table and are not listed.
+func (d *PGDialect) relations(ctx context.Context, schemas ...string) ([]relation, error) {
+	schemaNames := make([]interface{}, len(schemas))
+	for i := range schemas {
+		schemaNames[i] = schemas[i]
+	}
+
+	builder := relationsBuilder().
+		Where(lk.Condition("n.nspname").In(schemaNames...)).
+		And(lk.Raw("NOT c.relispartition")).
+		OrderBy(lk.Order("n.nspname"), lk.Order("c.relname")).
+		Comment("tables")
+
+	query, args := builder.Query()
+	var results []relation
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("unable to retrieve tables: %w", err)
+	}
+
+	return results, nil
+}
+
+// relation returns the relation of a table.
+func (d *PGDialect) relation(ctx context.Context, tableName string) (relation, error) {
+	schema, name := dialect.SplitName(tableName)
+	if schema == "" {
+		schema = defaultSchema
+	}
+
+	builder := relationsBuilder().
+		Where(lk.Condition("c.relname").Equal(name)).
+		And(lk.Condition("n.nspname").Equal(schema)).
+		Comment("table oid")
+
+	query, args := builder.Query()
+	var results []relation
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return relation{}, fmt.Errorf("unable to retrieve table %s oid: %w", tableName, err)
+	}
+
+	if len(results) == 0 {
+		return relation{}, fmt.Errorf("unable to retrieve table %s oid: table does not exist", tableName)
+	}
+
+	return results[0], nil
+}
+
+func relationsBuilder() builder.Select {
+	return lk.Select(
+		lk.Raw("c.oid::int8 AS oid"),
+		lk.Raw("n.nspname AS schema"),
+		lk.Raw("c.relname AS name"),
+		lk.Raw("c.relkind::text AS kind"),
+	).
+		From(lk.Table("pg_catalog.pg_class").As("c")).
+		Join(lk.Table("pg_catalog.pg_namespace").As("n"), lk.On("n.oid", "c.relnamespace")).
+		Where(lk.Raw("c.relkind IN ('r', 'p', 'v', 'm', 'f')"))
+}
+
+// introspect builds the tables of the given relations. Each kind of catalog object is
+// retrieved for all the relations in a single query and assembled in memory.
+func (d *PGDialect) introspect(ctx context.Context, relations []relation) (dialect.Tables, error) {
+	if len(relations) == 0 {
+		return dialect.Tables{}, nil
+	}
+
+	oids := make([]int64, len(relations))
+	for i := range relations {
+		oids[i] = relations[i].OID
+	}
+
+	columns, err := d.columns(ctx, oids...)
+	if err != nil {
+		return nil, err
+	}
+
+	referenceKeys, err := d.referenceKeys(ctx, oids...)
+	if err != nil {
+		return nil, err
+	}
+
+	foreignKeys, err := d.foreignKeys(ctx, oids...)
+	if err != nil {
+		return nil, err
+	}
+
+	primaryKeys, err := d.primaryKeys(ctx, oids...)
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueKeys, err := d.uniqueKeys(ctx, oids...)
+	if err != nil {
+		return nil, err
+	}
+
+	sequences, err := d.sequences(ctx, oids...)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make(dialect.Tables, len(relations))
+	for i := range relations {
+		oid := relations[i].OID
+		tableName := relations[i].TableName()
+		schema, _ := dialect.SplitName(tableName)
+
+		tables[i] = dialect.Table{
+			Name:          tableName,
+			Schema:        schema,
+			Kind:          tableKinds[relations[i].Kind],
+			Columns:       columns[oid],
+			PrimaryKeys:   primaryKeys[oid],
+			UniqueKeys:    uniqueKeys[oid],
+			ForeignKeys:   foreignKeys[oid],
+			ReferenceKeys: referenceKeys[oid],
+			Sequences:     sequences[oid],
+		}
+	}
+
+	tables.Link()
+
+	return tables, nil
+}
+
+// referenceKeys returns the "Referenced by" constraints of the given relations.
+func (d *PGDialect) referenceKeys(ctx context.Context, oids ...int64) (map[int64]dialect.ReferenceKeys, error) {
+	builder := lk.Select(
+		lk.Raw("r.confrelid::int8 AS oid"),
+		"r.conname",
+		lk.Raw("n2.nspname AS schema"),
+		lk.Raw("c2.relname AS table"),
+		lk.Raw("a.attname AS column"),
+		lk.Raw("af.attname AS referenced_column"),
+	).From(lk.Raw(`pg_constraint r
+  JOIN pg_class c2 ON c2.oid = r.conrelid
+  JOIN pg_namespace n2 ON n2.oid = c2.relnamespace
+  CROSS JOIN LATERAL unnest(r.conkey, r.confkey) WITH ORDINALITY AS k(attnum, confattnum, position)
+  JOIN pg_attribute a ON a.attrelid = r.conrelid AND a.attnum = k.attnum
+  JOIN pg_attribute af ON af.attrelid = r.confrelid AND af.attnum = k.confattnum`)).
+		Where(lk.Condition("r.confrelid").In(oidValues(oids)...)).
+		And(lk.Raw("r.contype = 'f'")).
+		And(lk.Raw("r.conparentid = 0")).
+		OrderBy(lk.Order("r.confrelid"), lk.Order("n2.nspname"), lk.Order("c2.relname"), lk.Order("r.conname"), lk.Order("k.position")).
+		Comment("reference keys")
+
+	query, args := builder.Query()
+	var results []struct {
+		OID              int64  `db:"oid"`
+		Conname          string `db:"conname"`
+		Schema           string `db:"schema"`
+		Table            string `db:"table"`
+		Column           string `db:"column"`
+		ReferencedColumn string `db:"referenced_column"`
+	}
+
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("unable to retrieve reference keys: %w", err)
+	}
+
+	referenceKeys := make(map[int64]dialect.ReferenceKeys, len(oids))
+	for i := range oids {
+		referenceKeys[oids[i]] = make(dialect.ReferenceKeys, 0)
+	}
+
+	for i := range results {
+		result := results[i]
+		columnReference := dialect.ColumnReference{
+			ColumnName:           result.Column,
+			ReferencedColumnName: result.ReferencedColumn,
+		}
+
+		keys := referenceKeys[result.OID]
+		tableName := qualifiedName(result.Schema, result.Table)
+		last := len(keys) - 1
+		if last >= 0 && keys[last].Name == result.Conname && keys[last].TableName == tableName {
+			keys[last].Columns = append(keys[last].Columns, columnReference)
+			continue
+		}
+
+		referenceKeys[result.OID] = append(keys, dialect.ReferenceKey{
+			Name:      result.Conname,
+			TableName: tableName,
+			Columns:   dialect.ColumnReferences{columnReference},
+		})
+	}
+
+	return referenceKeys, nil
+}
+
+// foreignKeys returns the foreign keys of the given relations.
+func (d *PGDialect) foreignKeys(ctx context.Context, oids ...int64) (map[int64]dialect.ForeignKeys, error) {
+	builder := lk.Select(
+		lk.Raw("r.conrelid::int8 AS oid"),
+		"r.conname",
+		lk.Raw("pg_catalog.pg_get_constraintdef(r.oid, true) AS condef"),
+		lk.Raw("n.nspname AS schema"),
+		lk.Raw("c.relname AS table"),
+		lk.Raw("a.attname AS column"),
+		lk.Raw("af.attname AS referenced_column"),
+	).
+		From(lk.Raw(`pg_catalog.pg_constraint r
+  JOIN pg_class c ON c.oid = r.confrelid
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  CROSS JOIN LATERAL unnest(r.conkey, r.confkey) WITH ORDINALITY AS k(attnum, confattnum, position)
+  JOIN pg_attribute a ON a.attrelid = r.conrelid AND a.attnum = k.attnum
+  JOIN pg_attribute af ON af.attrelid = r.confrelid AND af.attnum = k.confattnum`)).
+		Where(lk.Condition("r.conrelid").In(oidValues(oids)...)).
+		And(lk.Raw("r.contype = 'f'")).
+		And(lk.Raw("r.conparentid = 0")).
+		OrderBy(lk.Order("r.conrelid"), lk.Order("r.conname"), lk.Order("k.position")).
+		Comment("foreign keys")
+
+	query, args := builder.Query()
+	var results []struct {
+		OID              int64  `db:"oid"`
+		Conname          string `db:"conname"`
+		Condef           string `db:"condef"`
+		Schema           string `db:"schema"`
+		Table            string `db:"table"`
+		Column           string `db:"column"`
+		ReferencedColumn string `db:"referenced_column"`
+	}
+
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("unable to retrieve foreign keys: %w", err)
+	}
+
+	foreignKeys := make(map[int64]dialect.ForeignKeys, len(oids))
+	for i := range oids {
+		foreignKeys[oids[i]] = make(dialect.ForeignKeys, 0)
+	}
+
+	for i := range results {
+		result := results[i]
+		columnReference := dialect.ColumnReference{
+			ColumnName:           result.Column,
+			ReferencedColumnName: result.ReferencedColumn,
+		}
+
+		keys := foreignKeys[result.OID]
+		last := len(keys) - 1
+		if last >= 0 && keys[last].Name == result.Conname {
+			keys[last].Columns = append(keys[last].Columns, columnReference)
+			continue
+		}
+
+		foreignKeys[result.OID] = append(keys, dialect.ForeignKey{
+			Name:                result.Conname,
+			Definition:          result.Condef,
+			Columns:             dialect.ColumnReferences{columnReference},
+			ReferencedTableName: qualifiedName(result.Schema, result.Table),
+		})
+	}
+
+	return foreignKeys, nil
+}
+
+// primaryKeys returns the primary keys of the given relations.
+func (d *PGDialect) primaryKeys(ctx context.Context, oids ...int64) (map[int64][]dialect.PrimaryKey, error) {
+	builder := lk.Select(
+		lk.Raw("c.oid::int8 AS oid"),
+		lk.Raw("n.nspname AS schema"),
+		lk.Raw("c.relname AS table"),
+		lk.Raw("a.attname AS name"),
+		lk.Raw("format_type(a.atttypid, a.atttypmod) AS data_type"),
+	).
+		From(lk.Raw(`pg_index x
+  JOIN pg_class c ON c.oid = x.indrelid
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum = any(x.indkey)`)).
+		Where(lk.Condition("c.oid").In(oidValues(oids)...)).
+		And(lk.Raw("x.indisprimary")).
+		OrderBy(lk.Order("c.oid"), lk.Order("array_position(x.indkey::int2[], a.attnum)")).
+		Comment("primary keys")
+
+	query, args := builder.Query()
+	var results []struct {
+		OID      int64  `db:"oid"`
+		Schema   string `db:"schema"`
+		Table    string `db:"table"`
+		Name     string `db:"name"`
+		DataType string `db:"data_type"`
+	}
+
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("unable to retrieve primary keys: %w", err)
+	}
+
+	primaryKeys := make(map[int64][]dialect.PrimaryKey, len(oids))
+	for i := range oids {
+		primaryKeys[oids[i]] = make([]dialect.PrimaryKey, 0)
+	}
+
+	for i := range results {
+		primaryKeys[results[i].OID] = append(primaryKeys[results[i].OID], dialect.PrimaryKey{
+			Name:      results[i].Name,
+			DataType:  results[i].DataType,
+			TableName: qualifiedName(results[i].Schema, results[i].Table),
+		})
+	}
+
+	return primaryKeys, nil
+}
+
+// uniqueKeys returns the unique constraints and indexes of the given relations, partial and
+// expression indexes are ignored since they do not identify rows.
+func (d *PGDialect) uniqueKeys(ctx context.Context, oids ...int64) (map[int64][]dialect.UniqueKey, error) {
+	builder := lk.Select(
+		lk.Raw("x.indrelid::int8 AS oid"),
+		lk.Raw("i.relname AS name"),
+		lk.Raw("a.attname AS column"),
+	).
+		From(lk.Raw(`pg_index x
+  JOIN pg_class i ON i.oid = x.indexrelid
+  CROSS JOIN LATERAL unnest(x.indkey::int2[]) WITH ORDINALITY AS k(attnum, position)
+  JOIN pg_attribute a ON a.attrelid = x.indrelid AND a.attnum = k.attnum`)).
+		Where(lk.Condition("x.indrelid").In(oidValues(oids)...)).
+		And(lk.Raw("x.indisunique")).
+		And(lk.Raw("NOT x.indisprimary")).
+		And(lk.Raw("x.indpred IS NULL")).
+		And(lk.Raw("x.indexprs IS NULL")).
+		OrderBy(lk.Order("x.indrelid"), lk.Order("i.relname"), lk.Order("k.position")).
+		Comment("unique keys")
+
+	query, args := builder.Query()
+	var results []struct {
+		OID    int64  `db:"oid"`
+		Name   string `db:"name"`
+		Column string `db:"column"`
+	}
+
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("unable to retrieve unique keys: %w", err)
+	}
+
+	uniqueKeys := make(map[int64][]dialect.UniqueKey, len(oids))
+	for i := range oids {
+		uniqueKeys[oids[i]] = make([]dialect.UniqueKey, 0)
+	}
+
+	for i := range results {
+		keys := uniqueKeys[results[i].OID]
+		last := len(keys) - 1
+		if last >= 0 && keys[last].Name == results[i].Name {
+			keys[last].ColumnNames = append(keys[last].ColumnNames, results[i].Column)
+			continue
+		}
+
+		uniqueKeys[results[i].OID] = append(keys, dialect.UniqueKey{
+			Name:        results[i].Name,
+			ColumnNames: []string{results[i].Column},
+		})
+	}
+
+	return uniqueKeys, nil
+}
+
+// sequences returns the sequences owned by the columns of the given relations, serial columns own
+// their sequence through an automatic dependency and identity columns through an internal one.
+func (d *PGDialect) sequences(ctx context.Context, oids ...int64) (map[int64][]dialect.Sequence, error) {
+	builder := lk.Select(
+		lk.Raw("d.refobjid::int8 AS oid"),
+		lk.Raw("n.nspname AS schema"),
+		lk.Raw("s.relname AS name"),
+		lk.Raw("a.attname AS column"),
+	).
+		From(lk.Raw(`pg_depend d
+  JOIN pg_class s ON s.oid = d.objid AND s.relkind = 'S'
+  JOIN pg_namespace n ON n.oid = s.relnamespace
+  JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid`)).
+		Where(lk.Condition("d.refobjid").In(oidValues(oids)...)).
+		And(lk.Raw("d.classid = 'pg_class'::regclass")).
+		And(lk.Raw("d.refclassid = 'pg_class'::regclass")).
+		And(lk.Raw("d.deptype IN ('a', 'i')")).
+		OrderBy(lk.Order("d.refobjid"), lk.Order("a.attnum")).
+		Comment("sequences")
+
+	query, args := builder.Query()
+	var results []struct {
+		OID    int64  `db:"oid"`
+		Schema string `db:"schema"`
+		Name   string `db:"name"`
+		Column string `db:"column"`
+	}
+
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("unable to retrieve sequences: %w", err)
+	}
+
+	sequences := make(map[int64][]dialect.Sequence, len(oids))
+	for i := range results {
+		sequences[results[i].OID] = append(sequences[results[i].OID], dialect.Sequence{
+			Name:       qualifiedName(results[i].Schema, results[i].Name),
+			ColumnName: results[i].Column,
+		})
+	}
+
+	return sequences, nil
+}
+
+// columns returns the sorted columns of the given relations, the columns of every relation
+// are returned when no relation is given.
+func (d *PGDialect) columns(ctx context.Context, oids ...int64) (map[int64]dialect.Columns, error) {
+	builder := lk.Select(
+		lk.Raw("a.attrelid::int8 AS oid"),
+		lk.Raw("a.attname AS column_name"),
+		lk.Raw("pg_catalog.format_type(a.atttypid, a.atttypmod) AS data_type"),
+		lk.Raw(`(
+    SELECT pg_catalog.pg_get_expr(d.adbin, d.adrelid)
+    FROM pg_catalog.pg_attrdef d
+    WHERE d.adrelid = a.attrelid AND d.adnum = a.attnum
+    AND a.atthasdef
+  ) AS default`),
+		lk.Raw("NOT a.attnotnull AS is_nullable"),
+		lk.Raw("n.nspname AS schema_name"),
+		lk.Raw("c.relname AS table_name"),
+		lk.Raw("a.attnum as ordinal_position"),
+	).
+		From(lk.Table("pg_catalog.pg_attribute").As("a")).
+		Join(lk.Table("pg_catalog.pg_class").As("c"), lk.On("a.attrelid", "c.oid"), lk.LeftJoin).
+		Join(lk.Table("pg_catalog.pg_namespace").As("n"), lk.On("c.relnamespace", "n.oid"), lk.LeftJoin).
+		Where(lk.Condition("a.attnum").GreaterThan(0)).
+		And(lk.Condition("a.attisdropped").Equal(false)).
+		OrderBy(lk.Order("a.attrelid"), lk.Order("a.attnum"))
+
+	if len(oids) > 0 {
+		builder = builder.Where(lk.Condition("a.attrelid").In(oidValues(oids)...))
+	}
+
+	query, args := builder.Query()
+	var results []struct {
+		OID             int64          `db:"oid"`
+		ColumnName      string         `db:"column_name"`
+		IsNullable      bool           `db:"is_nullable"`
+		DataType        string         `db:"data_type"`
+		Default         sql.NullString `db:"default"`
+		OrdinalPosition int64          `db:"ordinal_position"`
+		SchemaName      string         `db:"schema_name"`
+		TableName       string         `db:"table_name"`
+	}
+
+	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+		return nil, fmt.Errorf("unable to retrieve columns: %w", err)
+	}
+
+	columns := make(map[int64]dialect.Columns, len(oids))
+	for i := range results {
+		result := results[i]
+
+		columns[result.OID] = append(columns[result.OID], dialect.Column{
+			Name:      result.ColumnName,
+			DataType:  result.DataType,
+			TableName: qualifiedName(result.SchemaName, result.TableName),
+			Position:  result.OrdinalPosition,
+			Nullable:  result.IsNullable,
+		})
+	}
+
+	return columns, nil
+}
+
+func oidValues(oids []int64) []interface{} {
+	values := make([]interface{}, len(oids))
+	for i := range oids {
+		values[i] = oids[i]
+	}
+
+	return values
+}
+
+// fingerprintQuery hashes the catalog rows read by the introspection of the given schemas.
+const fingerprintQuery = `WITH relations AS (
+  SELECT c.oid
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  WHERE n.nspname = ANY($1)
+)
+SELECT md5(coalesce(string_agg(state, ';' ORDER BY state), ''))
+FROM (
+  SELECT concat_ws(',', 'c', c.oid, n.nspname, c.relname, c.relkind, c.relispartition)
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  WHERE c.oid IN (SELECT oid FROM relations)
+  UNION ALL
+  SELECT concat_ws(',', 'a', a.attrelid, a.attnum, a.attname, a.atttypid, a.atttypmod, a.attnotnull, a.attisdropped)
+  FROM pg_catalog.pg_attribute a
+  WHERE a.attrelid IN (SELECT oid FROM relations)
+  UNION ALL
+  SELECT concat_ws(',', 'r', r.oid, r.conname, r.contype, r.conrelid, r.confrelid, r.conkey, r.confkey, r.conparentid)
+  FROM pg_catalog.pg_constraint r
+  WHERE r.conrelid IN (SELECT oid FROM relations) OR r.confrelid IN (SELECT oid FROM relations)
+  UNION ALL
+  SELECT concat_ws(',', 'x', x.indexrelid, x.indrelid, x.indisunique, x.indisprimary, x.indkey, x.indpred IS NULL, x.indexprs IS NULL)
+  FROM pg_catalog.pg_index x
+  WHERE x.indrelid IN (SELECT oid FROM relations)
+  UNION ALL
+  SELECT concat_ws(',', 'd', d.objid, d.refobjid, d.refobjsubid, d.deptype)
+  FROM pg_catalog.pg_depend d
+  WHERE d.refobjid IN (SELECT oid FROM relations)
+  AND d.classid = 'pg_class'::regclass
+  AND d.refclassid = 'pg_class'::regclass
+) AS catalog(state)`
+
+// Identity identifies the database server and database without credentials.
+func (d *PGDialect) Identity() string {
+	cfg := d.pool.Config().ConnConfig
+
+	return fmt.Sprintf("postgres://%s@%s:%d/%s", cfg.User, cfg.Host, cfg.Port, cfg.Database)
+}
+
+// SchemaFingerprint returns a hash of the catalog state of the given schemas, the public
+// schema is used by default.
+func (d *PGDialect) SchemaFingerprint(ctx context.Context, schemas ...string) (string, error) {
+	if len(schemas) == 0 {
+		schemas = []string{defaultSchema}
+	}
+
+	var fingerprint string
+	if err := d.queryRow(ctx, &fingerprint, fingerprintQuery, schemas); err != nil {
+		return "", fmt.Errorf("unable to compute schema fingerprint: %w", err)
+	}
+
+	return fingerprint, nil
+}
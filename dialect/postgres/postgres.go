@@ -2,49 +2,120 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
-	"regexp"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/georgysavva/scany/pgxscan"
-	"github.com/jackc/pgtype"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 	lk "github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/stmt"
+	"github.com/ulule/loukoum/v3/types"
 
 	"github.com/ulule/mover/dialect"
 )
 
-var fkRegexp = regexp.MustCompile(`FOREIGN KEY \((.*?)\) REFERENCES (?:(.*?)\.)?(.*?)\((.*?)\)`)
+// defaultSchema is the schema of tables which are not qualified.
+const defaultSchema = "public"
 
-// NewPGDialect initializes a new PGDialect instance.
+// copyThreshold is the number of rows from which BulkInsert copies rows to a staging table
+// instead of inserting them one by one. It equals the default batch size of loads: batches are
+// copied unless the batch size is lowered below it.
+const copyThreshold = 1000
+
+// stagingTableName is the temporary table receiving copied rows.
+const stagingTableName = "mover_staging"
+
+// tableKinds maps pg_class.relkind to table kinds.
+var tableKinds = map[string]dialect.TableKind{
+	"r": dialect.TableKindTable,
+	"p": dialect.TableKindPartitioned,
+	"v": dialect.TableKindView,
+	"m": dialect.TableKindMaterializedView,
+	"f": dialect.TableKindForeign,
+}
+
+func init() {
+	dialect.Register("postgres", NewPGDialect, "postgres", "postgresql")
+}
+
+// NewPGDialect initializes a new PGDialect instance, the size of the pool is configured by the
+// pool_max_conns parameter of the dsn.
 func NewPGDialect(ctx context.Context, dsn string) (dialect.Dialect, error) {
-	conn, err := pgx.Connect(ctx, dsn)
+	pool, err := pgxpool.Connect(ctx, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to database with dsn %s: %w", dsn, err)
 	}
 
 	return &PGDialect{
-		conn: conn,
+		pool: pool,
 	}, nil
 }
 
-// PGDialect manages a connection with PostgreSQL.
+// querier is implemented by the pool, its connections and transactions.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// PGDialect manages a pool of connections with PostgreSQL.
 type PGDialect struct {
-	conn *pgx.Conn
+	pool *pgxpool.Pool
+	// conn is the connection pinned by loads and bulk operations, so their transactions and temporary
+	// tables span every statement.
+	conn *pgxpool.Conn
+	// snapshot contains the transactions reading the snapshot shared by ResultSet calls, snapshotMu guards it
+	// and is held for reading by ResultSet calls so EndSnapshot waits for the queries reading the snapshot.
+	snapshot   *snapshot
+	snapshotMu sync.RWMutex
+	// mode is the load mode, resolved from LoadModeAuto on first use.
+	mode dialect.LoadMode
+	// tables are the tables of the load, LoadModeAuto only defers constraints when their foreign keys
+	// are deferrable.
+	tables dialect.Tables
+	// tx is the transaction spanning the load of every table in atomic or deferred mode.
+	tx pgx.Tx
+	// loaded are the tables loaded between BeginLoad and EndLoad by name, their sequences are reset
+	// once by EndLoad after the last batch of every table, sequence changes cannot be rolled back.
+	loaded map[string]dialect.Table
 }
 
-// Close closes a connection.
+// Close closes the connections of the pool.
 func (d *PGDialect) Close(ctx context.Context) error {
-	return d.conn.Close(ctx)
+	d.pool.Close()
+
+	return nil
 }
 
-// ResultSet executes a query and converts Rows in map[string]interface{}.
+// ResultSet executes a query and converts Rows in map[string]interface{}. Queries read the shared
+// snapshot between BeginSnapshot and EndSnapshot, it is safe for concurrent use.
 func (d *PGDialect) ResultSet(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
-	rows, err := d.query(ctx, query, args...)
+	d.snapshotMu.RLock()
+	defer d.snapshotMu.RUnlock()
+
+	var db querier
+	if s := d.snapshot; s != nil {
+		tx := <-s.readers
+		defer func() {
+			s.readers <- tx
+		}()
+
+		db = tx
+	} else {
+		db = d.db()
+	}
+
+	rows, err := db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve results : %w", err)
+		return nil, fmt.Errorf("unable to retrieve results : unable to execute query %s with args %v: %w", query, args, err)
 	}
+	defer rows.Close()
 
 	results := make([]map[string]interface{}, 0)
 	for rows.Next() {
@@ -56,15 +127,35 @@ func (d *PGDialect) ResultSet(ctx context.Context, query string, args ...interfa
 		results = append(results, result)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve results : %w", err)
+	}
+
 	return results, nil
 }
 
-// BulkInsert inserts multiple data a single database transaction. It disables triggers to avoid conflicts on
-// foreign constraints.
-func (d *PGDialect) BulkInsert(ctx context.Context, table dialect.Table, data []map[string]interface{}) error {
+// BulkInsert inserts multiple data a single database transaction. Depending on the load mode, it disables triggers
+// or defers constraints to avoid conflicts on foreign constraints.
+func (d *PGDialect) BulkInsert(ctx context.Context, table dialect.Table, data []map[string]interface{}, opts dialect.InsertOptions) error {
+	return d.acquire(ctx, func(ctx context.Context) error {
+		return d.bulkInsert(ctx, table, data, opts)
+	})
+}
+
+func (d *PGDialect) bulkInsert(ctx context.Context, table dialect.Table, data []map[string]interface{}, opts dialect.InsertOptions) error {
 	var err error
 
-	tx, err := d.conn.Begin(ctx)
+	if !table.Insertable() {
+		return fmt.Errorf("unable to insert to %s: %s is not insertable", table.Name, table.Kind)
+	}
+
+	// The load mode is resolved before the transaction begins since probing it runs transactions of its own.
+	mode, err := d.loadMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.begin(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to begin transaction on table %s: %w", table.Name, err)
 	}
@@ -73,13 +164,29 @@ func (d *PGDialect) BulkInsert(ctx context.Context, table dialect.Table, data []
 		err = tx.Rollback(ctx)
 	}()
 
-	if err := d.disableTriggers(ctx, table, func(ctx context.Context) error {
-		for i := range data {
-			if err := d.insert(ctx, table, data[i]); err != nil {
+	if err := d.relaxConstraints(ctx, mode, table, func(ctx context.Context) error {
+		if columns, ok := copyColumns(table, data); ok {
+			return d.copy(ctx, table, columns, data, opts)
+		}
+
+		if opts.ConflictStrategy() == dialect.ConflictFail {
+			if err := d.conflicts(ctx, table, data); err != nil {
 				return err
 			}
 		}
 
+		keyColumnNames := table.KeyColumnNames()
+		for i := range data {
+			inserted, err := d.insert(ctx, table, data[i], opts)
+			if err != nil {
+				return &dialect.RowError{TableName: table.Name, Index: i, Row: data[i], Err: err}
+			}
+
+			if inserted && opts.Inserted != nil && len(keyColumnNames) > 0 {
+				opts.Inserted(rowValues(data[i], keyColumnNames))
+			}
+		}
+
 		return nil
 	}); err != nil {
 		return err
@@ -89,13 +196,94 @@ func (d *PGDialect) BulkInsert(ctx context.Context, table dialect.Table, data []
 		return fmt.Errorf("unable to commit transaction on table %s: %w", table.Name, err)
 	}
 
-	if err := d.resetSequence(ctx, table); err != nil {
-		return fmt.Errorf("unable to reset sequence on table %s: %w", table.Name, err)
+	// Within a load, sequences are reset by EndLoad. Outside a load, they are reset after every call.
+	if d.loaded != nil {
+		d.loaded[table.Name] = table
+		return err
+	}
+
+	if err := d.resetSequences(ctx, table); err != nil {
+		return fmt.Errorf("unable to reset sequences on table %s: %w", table.Name, err)
+	}
+
+	return err
+}
+
+// BulkUpdate sets the columns of existing rows identified by the key of the table in a single database transaction.
+func (d *PGDialect) BulkUpdate(ctx context.Context, table dialect.Table, data []map[string]interface{}) error {
+	return d.acquire(ctx, func(ctx context.Context) error {
+		return d.bulkUpdate(ctx, table, data)
+	})
+}
+
+func (d *PGDialect) bulkUpdate(ctx context.Context, table dialect.Table, data []map[string]interface{}) error {
+	var err error
+
+	mode, err := d.loadMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction on table %s: %w", table.Name, err)
+	}
+
+	defer func() {
+		err = tx.Rollback(ctx)
+	}()
+
+	keyColumnNames := table.KeyColumnNames()
+	if err := d.relaxConstraints(ctx, mode, table, func(ctx context.Context) error {
+		for i := range data {
+			if err := d.update(ctx, table, keyColumnNames, data[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit transaction on table %s: %w", table.Name, err)
 	}
 
 	return err
 }
 
+// DanglingReferences returns the number of rows of a table referencing rows which do not exist through
+// a foreign key, and at most limit of these rows.
+func (d *PGDialect) DanglingReferences(ctx context.Context, table dialect.Table, foreignKey dialect.ForeignKey, limit int) (int64, []map[string]interface{}, error) {
+	clause := dialect.DanglingReferencesClause(table, foreignKey, quoteIdentifier)
+
+	var count int64
+	if err := d.queryRow(ctx, &count, "SELECT COUNT(*) "+clause); err != nil {
+		return 0, nil, fmt.Errorf("unable to count dangling references of %s: %w", table.Name, err)
+	}
+
+	if count == 0 || limit == 0 {
+		return count, nil, nil
+	}
+
+	rows, err := d.ResultSet(ctx, fmt.Sprintf("SELECT c.* %s LIMIT %d", clause, limit))
+	if err != nil {
+		return 0, nil, fmt.Errorf("unable to retrieve dangling references of %s: %w", table.Name, err)
+	}
+
+	return count, rows, nil
+}
+
+// RefreshMaterializedView refreshes a materialized view with the data of its underlying tables.
+func (d *PGDialect) RefreshMaterializedView(ctx context.Context, table dialect.Table) error {
+	if err := d.exec(ctx, fmt.Sprintf("REFRESH MATERIALIZED VIEW %s", table.Name)); err != nil {
+		return fmt.Errorf("unable to refresh materialized view %s: %w", table.Name, err)
+	}
+
+	return nil
+}
+
 // ReferenceKeys returns the "Referenced by" constraints of a table.
 func (d *PGDialect) ReferenceKeys(ctx context.Context, tableName string) (dialect.ReferenceKeys, error) {
 	oid, err := d.getTableOID(ctx, tableName)
@@ -103,34 +291,12 @@ func (d *PGDialect) ReferenceKeys(ctx context.Context, tableName string) (dialec
 		return nil, err
 	}
 
-	builder := lk.Select(
-		"conname",
-		lk.Raw("c2.relname AS table"),
-		lk.Raw("(SELECT attname FROM pg_attribute WHERE attrelid = r.conrelid AND ARRAY[attnum] <@ r.conkey) AS column"),
-	).From(lk.Raw("pg_constraint r, pg_class c, pg_class c2")).
-		Where(lk.Condition("r.confrelid").Equal(oid)).
-		And(lk.Raw("c.oid = r.confrelid")).
-		And(lk.Raw("c2.oid = r.conrelid")).
-		Comment("reference keys")
-	query, args := builder.Query()
-	var results []struct {
-		Conname string `db:"conname"`
-		Table   string `db:"table"`
-		Column  string `db:"column"`
-	}
-
-	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+	referenceKeys, err := d.referenceKeys(ctx, oid)
+	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve table %s reference keys: %w", tableName, err)
 	}
-	referenceKeys := make(dialect.ReferenceKeys, len(results))
-	for i := range referenceKeys {
-		referenceKeys[i] = dialect.ReferenceKey{
-			Name:       results[i].Conname,
-			TableName:  results[i].Table,
-			ColumnName: results[i].Column,
-		}
-	}
-	return referenceKeys, nil
+
+	return referenceKeys[oid], nil
 }
 
 // ForeignKeys returns the foreign keys of a table.
@@ -140,39 +306,12 @@ func (d *PGDialect) ForeignKeys(ctx context.Context, tableName string) (dialect.
 		return nil, err
 	}
 
-	builder := lk.Select("r.conname", lk.Raw("pg_catalog.pg_get_constraintdef(r.oid, true) AS condef")).
-		From(lk.Raw("pg_catalog.pg_constraint r, pg_namespace n, pg_class c")).
-		Where(lk.Condition("r.conrelid").Equal(oid)).
-		And(lk.Raw("r.contype = 'f'")).
-		And(lk.Raw("c.oid = confrelid")).
-		And(lk.Raw("n.oid = c.relnamespace")).
-		OrderBy(lk.Order("1")).
-		Comment("foreign keys")
-
-	query, args := builder.Query()
-	var results []struct {
-		Conname string `db:"conname"`
-		Condef  string `db:"condef"`
-	}
-
-	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+	foreignKeys, err := d.foreignKeys(ctx, oid)
+	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve table %s foreign keys: %w", tableName, err)
 	}
 
-	foreignKeys := make(dialect.ForeignKeys, len(results))
-	for i := range foreignKeys {
-		matches := fkRegexp.FindStringSubmatch(results[i].Condef)
-
-		foreignKeys[i] = dialect.ForeignKey{
-			Name:                 results[i].Conname,
-			Definition:           results[i].Condef,
-			ColumnName:           matches[1],
-			ReferencedTableName:  matches[3],
-			ReferencedColumnName: matches[4],
-		}
-	}
-
-	return foreignKeys, nil
+	return foreignKeys[oid], nil
 }
 
 // PrimaryKeyConstraint returns the primary key constraint of a table.
@@ -203,285 +342,857 @@ func (d *PGDialect) PrimaryKeys(ctx context.Context, tableName string) ([]dialec
 		return nil, err
 	}
 
-	builder := lk.Select(
-		lk.Raw("pg_attribute.attname AS name"),
-		lk.Raw("format_type(pg_attribute.atttypid, pg_attribute.atttypmod) AS data_type"),
-	).
-		From(lk.Raw("pg_index, pg_class, pg_attribute, pg_namespace")).
-		Where(lk.Condition("pg_class.oid").Equal(oid)).
-		And(lk.Raw("indrelid = pg_class.oid")).
-		And(lk.Raw("nspname = 'public'")).
-		And(lk.Raw("pg_class.relnamespace = pg_namespace.oid")).
-		And(lk.Raw("pg_attribute.attrelid = pg_class.oid")).
-		And(lk.Raw("pg_attribute.attnum = any(pg_index.indkey)")).
-		And(lk.Raw("indisprimary")).
-		Comment("primary keys")
+	primaryKeys, err := d.primaryKeys(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s primary keys: %w", tableName, err)
+	}
 
-	query, args := builder.Query()
-	var results []struct {
-		Name     string `db:"name"`
-		DataType string `db:"data_type"`
+	return primaryKeys[oid], nil
+}
+
+// UniqueKeys returns the unique constraints and indexes of a table.
+func (d *PGDialect) UniqueKeys(ctx context.Context, tableName string) ([]dialect.UniqueKey, error) {
+	oid, err := d.getTableOID(ctx, tableName)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := d.execQuery(ctx, &results, query, args...); err != nil {
-		return nil, fmt.Errorf("unable to retrieve table %s primary keys: %w", tableName, err)
+	uniqueKeys, err := d.uniqueKeys(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s unique keys: %w", tableName, err)
 	}
 
-	primaryKeys := make([]dialect.PrimaryKey, len(results))
-	for i := range results {
-		primaryKeys[i] = dialect.PrimaryKey{
-			Name:      results[i].Name,
-			DataType:  results[i].DataType,
-			TableName: tableName,
-		}
+	return uniqueKeys[oid], nil
+}
+
+// Sequences returns the sequences owned by the columns of a table.
+func (d *PGDialect) Sequences(ctx context.Context, tableName string) ([]dialect.Sequence, error) {
+	oid, err := d.getTableOID(ctx, tableName)
+	if err != nil {
+		return nil, err
 	}
 
-	return primaryKeys, err
+	sequences, err := d.sequences(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s sequences: %w", tableName, err)
+	}
+
+	return sequences[oid], nil
 }
 
-// Columns returns sorted columns with types of a table.
+// Columns returns sorted columns with types of a table, the columns of every table are
+// returned when the table name is empty.
 func (d *PGDialect) Columns(ctx context.Context, tableName string) ([]dialect.Column, error) {
-	builder := lk.Select(
-		lk.Raw("a.attname AS column_name"),
-		lk.Raw("pg_catalog.format_type(a.atttypid, a.atttypmod) AS data_type"),
-		lk.Raw(`(
-    SELECT pg_catalog.pg_get_expr(d.adbin, d.adrelid)
-    FROM pg_catalog.pg_attrdef d
-    WHERE d.adrelid = a.attrelid AND d.adnum = a.attnum
-    AND a.atthasdef
-  ) AS default`),
-		lk.Raw("a.attnotnull AS is_nullable"),
-		lk.Raw("c.relname AS table_name"),
-		lk.Raw("a.attnum as ordinal_position"),
-	).
-		From(lk.Table("pg_catalog.pg_attribute").As("a")).
-		Join(lk.Table("pg_catalog.pg_class").As("c"), lk.On("a.attrelid", "c.oid"), lk.LeftJoin).
-		Join(lk.Table("pg_catalog.pg_description").As("pgd"), lk.AndOn(lk.On("pgd.objoid", "a.attrelid"), lk.On("pgd.objsubid", "a.attnum")), lk.LeftJoin).
-		Where(lk.Condition("a.attnum").GreaterThan(0)).
-		And(lk.Condition("a.attisdropped").Equal(false)).
-		OrderBy(lk.Order("a.attnum"))
-
-	if tableName != "" {
-		oid, err := d.getTableOID(ctx, tableName)
+	if tableName == "" {
+		columns, err := d.columns(ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		builder = builder.Where(lk.Condition("a.attrelid").Equal(oid))
-	}
+		oids := make([]int64, 0, len(columns))
+		for oid := range columns {
+			oids = append(oids, oid)
+		}
+		sort.Slice(oids, func(i, j int) bool { return oids[i] < oids[j] })
 
-	query, args := builder.Query()
+		results := make([]dialect.Column, 0)
+		for i := range oids {
+			results = append(results, columns[oids[i]]...)
+		}
 
-	var results []struct {
-		ColumnName      string         `db:"column_name"`
-		IsNullable      bool           `db:"is_nullable"`
-		DataType        string         `db:"data_type"`
-		Default         sql.NullString `db:"default"`
-		OrdinalPosition int64          `db:"ordinal_position"`
-		TableName       string         `db:"table_name"`
+		return results, nil
 	}
 
-	if err := d.execQuery(ctx, &results, query, args...); err != nil {
+	oid, err := d.getTableOID(ctx, tableName)
+	if err != nil {
 		return nil, err
 	}
 
-	columns := make([]dialect.Column, len(results))
-	for i := range results {
-		result := results[i]
-
-		columns[i] = dialect.Column{
-			Name:      result.ColumnName,
-			DataType:  result.DataType,
-			TableName: result.TableName,
-			Position:  result.OrdinalPosition,
-			Nullable:  result.IsNullable,
-		}
+	columns, err := d.columns(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s columns: %w", tableName, err)
 	}
 
-	return columns, nil
+	return columns[oid], nil
 }
 
 // Table returns a table with its reference keys, foreign keys, columns and primary keys.
 func (d *PGDialect) Table(ctx context.Context, tableName string) (dialect.Table, error) {
-	columns, err := d.Columns(ctx, tableName)
+	rel, err := d.relation(ctx, tableName)
 	if err != nil {
 		return dialect.Table{}, err
 	}
 
-	table := dialect.Table{
-		Name:    tableName,
-		Columns: columns,
-	}
-	table.ReferenceKeys, err = d.ReferenceKeys(ctx, tableName)
+	tables, err := d.introspect(ctx, []relation{rel})
 	if err != nil {
 		return dialect.Table{}, err
 	}
 
-	table.ForeignKeys, err = d.ForeignKeys(ctx, tableName)
+	return tables[0], nil
+}
+
+// Tables returns all the tables from the given schemas, the public schema is used by default.
+// The catalog is read with one query per kind of object whatever the number of tables.
+func (d *PGDialect) Tables(ctx context.Context, schemas ...string) (dialect.Tables, error) {
+	if len(schemas) == 0 {
+		schemas = []string{defaultSchema}
+	}
+
+	relations, err := d.relations(ctx, schemas...)
 	if err != nil {
-		return dialect.Table{}, err
+		return nil, err
+	}
+
+	return d.introspect(ctx, relations)
+}
+
+func (d *PGDialect) execQuery(ctx context.Context, result interface{}, query string, args ...interface{}) error {
+	if err := pgxscan.Select(ctx, d.db(), result, query, args...); err != nil {
+		return fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
 	}
 
-	table.PrimaryKeys, err = d.PrimaryKeys(ctx, tableName)
+	return nil
+}
+
+func (d *PGDialect) queryRow(ctx context.Context, result interface{}, query string, args ...interface{}) error {
+	if err := d.db().QueryRow(ctx, query, args...).Scan(result); err != nil {
+		return fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+	}
+
+	return nil
+}
+
+func (d *PGDialect) query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := d.db().Query(ctx, query, args...)
 	if err != nil {
-		return dialect.Table{}, err
+		return nil, fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
 	}
 
-	return table, nil
+	return rows, nil
 }
 
-// Tables returns all the tables from the database.
-func (d *PGDialect) Tables(ctx context.Context) (dialect.Tables, error) {
-	builder := lk.Select("c.relname").
-		From(lk.Table("pg_catalog.pg_class").As("c")).
-		Join(lk.Table("pg_namespace").As("n"), lk.On("n.oid", "c.relnamespace")).
-		Where(lk.Raw("relkind = 'r'")).
-		And(lk.Condition("n.nspname").Equal("public")).
-		Comment("tables")
+func (d *PGDialect) getTableOID(ctx context.Context, tableName string) (int64, error) {
+	rel, err := d.relation(ctx, tableName)
+	if err != nil {
+		return 0, err
+	}
 
-	query, args := builder.Query()
+	return rel.OID, nil
+}
 
-	var tableNames []string
-	if err := d.execQuery(ctx, &tableNames, query, args...); err != nil {
-		return nil, fmt.Errorf("unable to execute query %s: %w", builder.String(), err)
+func (d *PGDialect) exec(ctx context.Context, query string, args ...interface{}) error {
+	if _, err := d.db().Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
 	}
 
-	columns, err := d.Columns(ctx, "")
+	return nil
+}
+
+// execRows executes a query and returns the number of affected rows.
+func (d *PGDialect) execRows(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := d.db().Exec(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve columns: %w", err)
+		return 0, fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
 	}
 
-	sortedColumns := make(map[string]dialect.Columns)
-	for i := range columns {
-		tableName := columns[i].TableName
-		_, ok := sortedColumns[tableName]
-		if !ok {
-			sortedColumns[tableName] = make(dialect.Columns, 0)
-		}
-		sortedColumns[tableName] = append(sortedColumns[tableName], columns[i])
+	return tag.RowsAffected(), nil
+}
+
+// insert inserts a row and returns true when it did not exist before.
+func (d *PGDialect) insert(ctx context.Context, table dialect.Table, data map[string]interface{}, opts dialect.InsertOptions) (bool, error) {
+	pairs, err := valuesToPairs(table, data)
+	if err != nil {
+		return false, fmt.Errorf("unable to convert %v to pairs: %w", data, err)
 	}
 
-	tables := make(dialect.Tables, len(tableNames))
-	for i := range tableNames {
-		sort.Sort(sortedColumns[tableNames[i]])
-		tables[i] = dialect.Table{
-			Name:    tableNames[i],
-			Columns: sortedColumns[tableNames[i]],
+	existed := false
+
+	builder := lk.Insert(table.Name).Set(pairs...)
+
+	switch opts.ConflictStrategy() {
+	case dialect.ConflictNothing:
+		// Without a primary key, rows conflicting on any unique constraint are skipped.
+		primaryKeys := table.PrimaryKeyColumnNames()
+		conflict := make([]interface{}, 0, len(primaryKeys)+1)
+		for i := range primaryKeys {
+			conflict = append(conflict, primaryKeys[i])
 		}
-		tables[i].ReferenceKeys, err = d.ReferenceKeys(ctx, tableNames[i])
+		conflict = append(conflict, lk.DoNothing())
+
+		builder = builder.OnConflict(conflict...)
+	case dialect.ConflictSkipDuplicates:
+		exists, err := d.exists(ctx, table, pairs)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
 
-		tables[i].ForeignKeys, err = d.ForeignKeys(ctx, tableNames[i])
+		if exists {
+			return false, nil
+		}
+	case dialect.ConflictUpdate:
+		keyColumnNames := table.KeyColumnNames()
+		if len(keyColumnNames) == 0 {
+			break
+		}
+
+		// Updated rows are not reported as inserted.
+		if keys, ok := keyPairs(keyColumnNames, data); ok && opts.Inserted != nil {
+			if existed, err = d.exists(ctx, table, keys); err != nil {
+				return false, err
+			}
+		}
+
+		conflict := make([]interface{}, 0, len(keyColumnNames)+1)
+		for i := range keyColumnNames {
+			conflict = append(conflict, keyColumnNames[i])
+		}
+
+		updates := opts.UpdateColumnNames(table, pairsColumnNames(pairs))
+		if len(updates) == 0 {
+			conflict = append(conflict, lk.DoNothing())
+		} else {
+			sets := make([]interface{}, len(updates))
+			for i := range updates {
+				sets[i] = lk.Pair(updates[i], lk.Raw("EXCLUDED."+quoteIdentifier(updates[i])))
+			}
+			conflict = append(conflict, lk.DoUpdate(sets...))
+		}
+
+		builder = builder.OnConflict(conflict...)
+	case dialect.ConflictReplace:
+		deleted, err := d.delete(ctx, table, data)
 		if err != nil {
-			return nil, err
+			return false, err
 		}
+		existed = deleted > 0
+	case dialect.ConflictAppend, dialect.ConflictFail:
+	default:
+		return false, fmt.Errorf("unable to insert to %s: %w", table.Name, opts.Conflict.Validate())
+	}
 
-		tables[i].PrimaryKeys, err = d.PrimaryKeys(ctx, tableNames[i])
+	query, args := builder.Query()
+	inserted, err := d.execRows(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("unable to insert %v+ to %s:%w", pairs, table.Name, err)
+	}
+
+	return inserted > 0 && !existed, nil
+}
+
+// copy copies rows to a staging table with COPY FROM and moves them to the table with a single
+// INSERT ... SELECT honoring the conflict strategy. Staging columns are text and values are cast to
+// the column types, rows are parsed by the server as they are with INSERT.
+func (d *PGDialect) copy(ctx context.Context, table dialect.Table, columns []string, data []map[string]interface{}, opts dialect.InsertOptions) error {
+	var (
+		staging     = quoteIdentifier(stagingTableName)
+		definitions = make([]string, len(columns))
+		names       = make([]string, len(columns))
+		values      = make([]string, len(columns))
+	)
+	for i := range columns {
+		definitions[i] = quoteIdentifier(columns[i]) + " text"
+		names[i] = quoteIdentifier(columns[i])
+		values[i] = fmt.Sprintf("s.%s::%s", quoteIdentifier(columns[i]), table.Columns.Get(columns[i]).DataType)
+	}
+
+	if err := d.exec(ctx, fmt.Sprintf("CREATE TEMPORARY TABLE %s (%s) ON COMMIT DROP",
+		staging, strings.Join(definitions, ", "))); err != nil {
+		return fmt.Errorf("unable to create staging table for %s: %w", table.Name, err)
+	}
+
+	rows := make([][]interface{}, len(data))
+	for i := range data {
+		row, err := textValues(d.conn.Conn().ConnInfo(), table, columns, data[i])
 		if err != nil {
-			return nil, err
+			return err
 		}
+
+		rows[i] = row
 	}
 
-	tablesMap := make(map[string]dialect.Table, len(tables))
-	for i := range tables {
-		tablesMap[tables[i].Name] = tables[i]
+	// Staging rows match existing rows on the key of the table when every key column is copied.
+	keyColumnNames := table.KeyColumnNames()
+	keyConditions := make([]string, len(keyColumnNames))
+	keyValues := make([]string, len(keyColumnNames))
+	keyIndexes := make([]int, len(keyColumnNames))
+	for i := range keyColumnNames {
+		j := sort.SearchStrings(columns, keyColumnNames[i])
+		if j == len(columns) || columns[j] != keyColumnNames[i] {
+			keyColumnNames, keyConditions, keyValues, keyIndexes = nil, nil, nil, nil
+			break
+		}
+
+		keyConditions[i] = fmt.Sprintf("t.%s = %s", names[j], values[j])
+		keyValues[i] = fmt.Sprintf("to_json(%s)", values[j])
+		keyIndexes[i] = j
 	}
 
-	for i := range tables {
-		for j := range tables[i].ReferenceKeys {
-			tables[i].ReferenceKeys[j].Table = tablesMap[tables[i].ReferenceKeys[j].TableName]
+	// A single statement cannot update or replace a row twice, the last row of a key wins as when
+	// rows are inserted one by one.
+	if strategy := opts.ConflictStrategy(); len(keyColumnNames) > 0 && (strategy == dialect.ConflictUpdate || strategy == dialect.ConflictReplace) {
+		rows = lastRowsByKey(rows, keyIndexes)
+	}
+
+	if _, err := d.db().CopyFrom(ctx, pgx.Identifier{stagingTableName}, columns, pgx.CopyFromRows(rows)); err != nil {
+		return fmt.Errorf("unable to copy %d rows to staging table for %s: %w", len(rows), table.Name, err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s s",
+		quoteIdentifier(table.Name), strings.Join(names, ", "), strings.Join(values, ", "), staging)
+
+	// Rows are reported as inserted unless they existed before, updated and replaced rows existed.
+	// Keys are reported in JSON like the loaded rows.
+	var existing map[string]struct{}
+	report := opts.Inserted != nil && len(keyColumnNames) > 0
+	if strategy := opts.ConflictStrategy(); report && (strategy == dialect.ConflictUpdate || strategy == dialect.ConflictReplace) {
+		keys, err := d.stagingKeys(ctx, table, keyValues, keyConditions)
+		if err != nil {
+			return err
+		}
+
+		existing = make(map[string]struct{}, len(keys))
+		for i := range keys {
+			existing[fmt.Sprint(keys[i])] = struct{}{}
+		}
+	}
+
+	switch opts.ConflictStrategy() {
+	case dialect.ConflictNothing:
+		// Without a primary key, rows conflicting on any unique constraint are skipped.
+		primaryKeys := table.PrimaryKeyColumnNames()
+		if len(primaryKeys) > 0 {
+			query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(quoteIdentifiers(primaryKeys), ", "))
+		} else {
+			query += " ON CONFLICT DO NOTHING"
+		}
+	case dialect.ConflictSkipDuplicates:
+		// Types without equality operator such as json are compared as text.
+		var (
+			distinct   = make([]string, len(columns))
+			conditions = make([]string, len(columns))
+		)
+		for i := range columns {
+			dataType := table.Columns.Get(columns[i]).DataType
+			distinct[i] = comparableExpression(values[i], dataType)
+			conditions[i] = fmt.Sprintf("%s IS NOT DISTINCT FROM %s", comparableExpression("t."+names[i], dataType), distinct[i])
+		}
+
+		query = fmt.Sprintf("INSERT INTO %s (%s) SELECT DISTINCT ON (%s) %s FROM %s s WHERE NOT EXISTS (SELECT 1 FROM %s t WHERE %s)",
+			quoteIdentifier(table.Name), strings.Join(names, ", "), strings.Join(distinct, ", "), strings.Join(values, ", "), staging,
+			quoteIdentifier(table.Name), strings.Join(conditions, " AND "))
+	case dialect.ConflictUpdate:
+		if len(keyColumnNames) == 0 {
+			break
+		}
+
+		updates := opts.UpdateColumnNames(table, columns)
+		if len(updates) == 0 {
+			query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(quoteIdentifiers(keyColumnNames), ", "))
+			break
 		}
 
-		for j := range tables[i].ForeignKeys {
-			tables[i].ForeignKeys[j].ReferencedTable = tablesMap[tables[i].ForeignKeys[j].ReferencedTableName]
+		sets := make([]string, len(updates))
+		for i := range updates {
+			sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", quoteIdentifier(updates[i]), quoteIdentifier(updates[i]))
 		}
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+			strings.Join(quoteIdentifiers(keyColumnNames), ", "), strings.Join(sets, ", "))
+	case dialect.ConflictReplace:
+		if len(keyColumnNames) == 0 {
+			break
+		}
+
+		if err := d.exec(ctx, fmt.Sprintf("DELETE FROM %s t USING %s s WHERE %s",
+			quoteIdentifier(table.Name), staging, strings.Join(keyConditions, " AND "))); err != nil {
+			return fmt.Errorf("unable to delete staging rows from %s: %w", table.Name, err)
+		}
+	case dialect.ConflictFail:
+		if len(keyColumnNames) == 0 {
+			break
+		}
+
+		if err := d.stagingConflicts(ctx, table, keyColumnNames, keyConditions); err != nil {
+			return err
+		}
+	case dialect.ConflictAppend:
+	default:
+		return fmt.Errorf("unable to insert to %s: %w", table.Name, opts.Conflict.Validate())
 	}
 
-	return tables, nil
+	if report {
+		returning := make([]string, len(keyColumnNames))
+		for i := range keyColumnNames {
+			returning[i] = fmt.Sprintf("to_json(%s)", quoteIdentifier(keyColumnNames[i]))
+		}
+
+		query += " RETURNING " + strings.Join(returning, ", ")
+		if err := d.reportInserted(ctx, query, existing, opts.Inserted); err != nil {
+			return fmt.Errorf("unable to insert staging rows to %s: %w", table.Name, err)
+		}
+	} else if err := d.exec(ctx, query); err != nil {
+		return fmt.Errorf("unable to insert staging rows to %s: %w", table.Name, err)
+	}
+
+	if err := d.exec(ctx, fmt.Sprintf("DROP TABLE %s", staging)); err != nil {
+		return fmt.Errorf("unable to drop staging table for %s: %w", table.Name, err)
+	}
+
+	return nil
 }
 
-func (d *PGDialect) execQuery(ctx context.Context, result interface{}, query string, args ...interface{}) error {
-	if err := pgxscan.Select(ctx, d.conn, result, query, args...); err != nil {
-		return fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+// update sets the columns of the row with the same key as the given row.
+func (d *PGDialect) update(ctx context.Context, table dialect.Table, keyColumnNames []string, data map[string]interface{}) error {
+	keys, ok := keyPairs(keyColumnNames, data)
+	if !ok {
+		return fmt.Errorf("unable to update %v of %s: key is missing", data, table.Name)
+	}
+
+	values := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if !containsString(keyColumnNames, k) {
+			values[k] = v
+		}
+	}
+
+	pairs, err := valuesToPairs(table, values)
+	if err != nil {
+		return fmt.Errorf("unable to convert %v to pairs: %w", values, err)
+	}
+
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	builder := lk.Update(table.Name).Set(pairs...)
+	for i := range keys {
+		pair := keys[i].(types.Pair)
+		builder = builder.Where(lk.Condition(pair.Key.(string)).Equal(pair.Value))
+	}
+
+	query, args := builder.Query()
+	if err := d.exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to update %v of %s: %w", pairsValues(keys), table.Name, err)
 	}
 
 	return nil
 }
 
-func (d *PGDialect) queryRow(ctx context.Context, result interface{}, query string, args ...interface{}) error {
-	if err := d.conn.QueryRow(ctx, query, args...).Scan(result); err != nil {
-		return fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+// delete deletes the row with the same key as the given row and returns the number of deleted rows,
+// rows with a NULL key conflict with no row.
+func (d *PGDialect) delete(ctx context.Context, table dialect.Table, data map[string]interface{}) (int64, error) {
+	pairs, ok := keyPairs(table.KeyColumnNames(), data)
+	if !ok {
+		return 0, nil
+	}
+
+	builder := lk.Delete(table.Name)
+	for i := range pairs {
+		pair := pairs[i].(types.Pair)
+		builder = builder.Where(lk.Condition(pair.Key.(string)).Equal(pair.Value))
+	}
+
+	query, args := builder.Query()
+	deleted, err := d.execRows(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("unable to delete %v from %s: %w", pairs, table.Name, err)
+	}
+
+	return deleted, nil
+}
+
+// BulkDelete deletes the rows with the given keys in a single database transaction.
+func (d *PGDialect) BulkDelete(ctx context.Context, table dialect.Table, keys [][]interface{}) error {
+	return d.acquire(ctx, func(ctx context.Context) error {
+		return d.bulkDelete(ctx, table, keys)
+	})
+}
+
+func (d *PGDialect) bulkDelete(ctx context.Context, table dialect.Table, keys [][]interface{}) error {
+	var err error
+
+	mode, err := d.loadMode(ctx)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction on table %s: %w", table.Name, err)
+	}
+
+	defer func() {
+		err = tx.Rollback(ctx)
+	}()
+
+	keyColumnNames := table.KeyColumnNames()
+	if err := d.relaxConstraints(ctx, mode, table, func(ctx context.Context) error {
+		for i := range keys {
+			data := make(map[string]interface{}, len(keyColumnNames))
+			for j := range keyColumnNames {
+				data[keyColumnNames[j]] = keys[i][j]
+			}
+
+			if _, err := d.delete(ctx, table, data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit transaction on table %s: %w", table.Name, err)
+	}
+
+	return err
+}
+
+// conflicts returns a ConflictError reporting the rows conflicting on the key of the table,
+// nil when no row conflicts.
+func (d *PGDialect) conflicts(ctx context.Context, table dialect.Table, data []map[string]interface{}) error {
+	keyColumnNames := table.KeyColumnNames()
+	keys := make([][]interface{}, 0)
+	for i := range data {
+		pairs, ok := keyPairs(keyColumnNames, data[i])
+		if !ok {
+			continue
+		}
+
+		exists, err := d.exists(ctx, table, pairs)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			keys = append(keys, pairsValues(pairs))
+		}
+	}
+
+	if len(keys) > 0 {
+		return &dialect.ConflictError{TableName: table.Name, ColumnNames: keyColumnNames, Keys: keys}
 	}
 
 	return nil
 }
 
-func (d *PGDialect) query(ctx context.Context, query string, args ...interface{}) (pgx.Rows, error) {
-	rows, err := d.conn.Query(ctx, query, args...)
+// stagingConflicts returns a ConflictError reporting the staging rows conflicting on the key of the table.
+func (d *PGDialect) stagingConflicts(ctx context.Context, table dialect.Table, keyColumnNames, keyConditions []string) error {
+	selected := make([]string, len(keyColumnNames))
+	for i := range keyColumnNames {
+		selected[i] = "s." + quoteIdentifier(keyColumnNames[i])
+	}
+
+	keys, err := d.stagingKeys(ctx, table, selected, keyConditions)
 	if err != nil {
-		return nil, fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+		return err
 	}
 
-	return rows, nil
+	if len(keys) > 0 {
+		return &dialect.ConflictError{TableName: table.Name, ColumnNames: keyColumnNames, Keys: keys}
+	}
+
+	return nil
 }
 
-func (d *PGDialect) getTableOID(ctx context.Context, tableName string) (int64, error) {
-	builder := lk.Select("c.oid").
-		From(lk.Table("pg_catalog.pg_class").As("c")).
-		Join(lk.Table("pg_catalog.pg_namespace").As("n"), lk.On("n.oid", "c.relnamespace"), lk.LeftJoin).
-		Where(lk.Raw("pg_catalog.pg_table_is_visible(c.oid)")).
-		And(lk.Condition("c.relname").Equal(tableName)).
-		And(lk.Raw("c.relkind IN ('r', 'v', 'm', 'f', 'p')")).
-		Comment("table oid")
+// stagingKeys returns the selected values of the staging rows matching existing rows on the key conditions.
+func (d *PGDialect) stagingKeys(ctx context.Context, table dialect.Table, selected, keyConditions []string) ([][]interface{}, error) {
+	rows, err := d.query(ctx, fmt.Sprintf("SELECT %s FROM %s s JOIN %s t ON %s",
+		strings.Join(selected, ", "), quoteIdentifier(stagingTableName), quoteIdentifier(table.Name),
+		strings.Join(keyConditions, " AND ")))
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve conflicting rows of %s: %w", table.Name, err)
+	}
+	defer rows.Close()
+
+	keys := make([][]interface{}, 0)
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve conflicting rows of %s: %w", table.Name, err)
+		}
+
+		keys = append(keys, values)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve conflicting rows of %s: %w", table.Name, err)
+	}
+
+	return keys, nil
+}
+
+// reportInserted executes an insert query returning the keys of the inserted rows and reports
+// the ones which did not exist before.
+func (d *PGDialect) reportInserted(ctx context.Context, query string, existing map[string]struct{}, inserted func([]interface{})) error {
+	rows, err := d.query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		values, err := rows.Values()
+		if err != nil {
+			return err
+		}
+
+		if _, ok := existing[fmt.Sprint(values)]; !ok {
+			inserted(values)
+		}
+	}
+
+	return rows.Err()
+}
+
+// exists returns true when a row of the table is identical to the given pairs, NULL values included.
+// Values of types without equality operator such as json are compared as text.
+func (d *PGDialect) exists(ctx context.Context, table dialect.Table, pairs []interface{}) (bool, error) {
+	builder := lk.Select(lk.Raw("1")).From(table.Name).Limit(1)
+	for i := range pairs {
+		pair := pairs[i].(types.Pair)
+		columnName := pair.Key.(string)
+
+		dataType := table.Columns.Get(columnName).DataType
+		if !comparedAsText(dataType) {
+			builder = builder.Where(lk.Condition(columnName).IsNotDistinctFrom(pair.Value))
+			continue
+		}
+
+		value, err := textValue(d.conn.Conn().ConnInfo(), dataType, pair.Value)
+		if err != nil {
+			return false, err
+		}
+
+		builder = builder.Where(stmt.NewInfixExpression(lk.Raw(comparableExpression(quoteIdentifier(columnName), dataType)),
+			stmt.NewComparisonOperator(types.IsNotDistinctFrom), stmt.NewExpression(value)))
+	}
+
 	query, args := builder.Query()
+	rows, err := d.query(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
 
-	var result pgtype.OID
-	if err := d.queryRow(ctx, &result, query, args...); err != nil {
-		return 0, fmt.Errorf("unable to retrieve table %s oid: %w", tableName, err)
+	exists := rows.Next()
+
+	return exists, rows.Err()
+}
+
+// BeginLoad starts the load of a dump, LoadModeAuto selects the first mode allowed by the privileges
+// of the user. Atomic loads and the deferred mode load every table in a single transaction committed by EndLoad.
+func (d *PGDialect) BeginLoad(ctx context.Context, opts dialect.LoadOptions) (dialect.LoadMode, error) {
+	if err := opts.Mode.Validate(); err != nil {
+		return "", err
+	}
+
+	// The connection is pinned until EndLoad.
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to acquire connection: %w", err)
 	}
+	d.conn = conn
 
-	raw, err := result.Value()
+	d.mode, d.tables, d.loaded = opts.Mode, opts.Tables, make(map[string]dialect.Table)
+	mode, err := d.loadMode(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("unable to retrieve table %s oid from driver value: %w", tableName, err)
+		d.release()
+		return "", err
 	}
 
-	val, ok := raw.(int64)
-	if ok {
-		return val, nil
+	if opts.Atomic || mode == dialect.LoadModeDeferred {
+		d.tx, err = d.db().Begin(ctx)
+		if err != nil {
+			d.release()
+			return "", fmt.Errorf("unable to begin load transaction: %w", err)
+		}
 	}
 
-	return 0, fmt.Errorf("unable to cast %v to int64", val)
+	return mode, nil
 }
 
-func (d *PGDialect) exec(ctx context.Context, query string, args ...interface{}) error {
-	if _, err := d.conn.Exec(ctx, query, args...); err != nil {
-		return fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+// EndLoad commits the load transaction and resets the sequences of loaded tables, or rolls it back
+// when the load failed. Without a load transaction, the batches committed before a failure are kept
+// and the sequences of their tables are reset.
+func (d *PGDialect) EndLoad(ctx context.Context, loadErr error) error {
+	defer d.release()
+
+	tx, loaded := d.tx, d.loaded
+	d.tx, d.mode, d.tables, d.loaded = nil, "", nil, nil
+	if tx == nil {
+		return d.resetLoadedSequences(ctx, loaded)
+	}
+
+	if loadErr != nil {
+		if err := tx.Rollback(ctx); err != nil {
+			return fmt.Errorf("unable to rollback load transaction: %w", err)
+		}
+
+		return loadErr
+	}
+
+	// Deferred constraints are checked on commit. setval is not rolled back, sequences are only reset
+	// once the rows are committed.
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("unable to commit load transaction: %w", err)
+	}
+
+	return d.resetLoadedSequences(ctx, loaded)
+}
+
+// resetLoadedSequences resets the sequences of loaded tables in name order.
+func (d *PGDialect) resetLoadedSequences(ctx context.Context, loaded map[string]dialect.Table) error {
+	tableNames := make([]string, 0, len(loaded))
+	for tableName := range loaded {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		if err := d.resetSequences(ctx, loaded[tableName]); err != nil {
+			return fmt.Errorf("unable to reset sequences on table %s: %w", tableName, err)
+		}
 	}
 
 	return nil
 }
 
-func (d *PGDialect) insert(ctx context.Context, table dialect.Table, data map[string]interface{}) error {
-	pairs, err := valuesToPairs(table, data)
+// begin begins a transaction, nested in the load transaction when there is one.
+func (d *PGDialect) begin(ctx context.Context) (pgx.Tx, error) {
+	if d.tx != nil {
+		return d.tx.Begin(ctx)
+	}
+
+	return d.db().Begin(ctx)
+}
+
+// db returns the pinned connection, or the pool when no connection is pinned.
+func (d *PGDialect) db() querier {
+	if d.conn != nil {
+		return d.conn
+	}
+
+	return d.pool
+}
+
+// acquire pins a connection of the pool for the statements of f, the connection pinned by BeginLoad is reused.
+func (d *PGDialect) acquire(ctx context.Context, f func(ctx context.Context) error) error {
+	if d.conn != nil {
+		return f(ctx)
+	}
+
+	conn, err := d.pool.Acquire(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to convert %v to pairs: %w", data, err)
+		return fmt.Errorf("unable to acquire connection: %w", err)
 	}
+	d.conn = conn
+	defer d.release()
 
-	query, args := lk.Insert(table.Name).
-		Set(pairs...).
-		OnConflict(table.PrimaryKeyColumnName(), lk.DoNothing()).
-		Query()
-	if err := d.exec(ctx, query, args...); err != nil {
-		return fmt.Errorf("unable to insert %v+ to %s:%w", pairs, table.Name, err)
+	return f(ctx)
+}
+
+// release releases the pinned connection to the pool.
+func (d *PGDialect) release() {
+	if d.conn != nil {
+		d.conn.Release()
+		d.conn = nil
 	}
+}
 
-	return nil
+// loadMode resolves LoadModeAuto: superusers disable triggers, other users set session_replication_role
+// when they are allowed to (PostgreSQL 15 and later grant it with GRANT SET) and defer constraints otherwise.
+// Constraints which are not DEFERRABLE cannot be deferred, tables are then loaded in ordered mode.
+// It must be called outside of the transactions of bulk operations.
+func (d *PGDialect) loadMode(ctx context.Context) (dialect.LoadMode, error) {
+	if d.mode != "" && d.mode != dialect.LoadModeAuto {
+		return d.mode, nil
+	}
+
+	var superuser bool
+	if err := d.queryRow(ctx, &superuser, "SELECT rolsuper FROM pg_catalog.pg_roles WHERE rolname = current_user"); err != nil {
+		return "", fmt.Errorf("unable to retrieve user privileges: %w", err)
+	}
+
+	switch {
+	case superuser:
+		d.mode = dialect.LoadModeDisableTriggers
+	case d.canSetReplicationRole(ctx):
+		d.mode = dialect.LoadModeReplica
+	default:
+		deferrable, err := d.deferrable(ctx, d.tables)
+		if err != nil {
+			return "", err
+		}
+
+		d.mode = dialect.LoadModeOrdered
+		if deferrable {
+			d.mode = dialect.LoadModeDeferred
+		}
+	}
+
+	return d.mode, nil
+}
+
+// deferrable returns true when every foreign key of the tables is declared as DEFERRABLE.
+func (d *PGDialect) deferrable(ctx context.Context, tables dialect.Tables) (bool, error) {
+	if len(tables) == 0 {
+		return true, nil
+	}
+
+	tableNames := make([]string, len(tables))
+	for i := range tables {
+		tableNames[i] = quoteIdentifier(tables[i].Name)
+	}
+
+	var count int64
+	if err := d.queryRow(ctx, &count, `SELECT COUNT(*) FROM pg_catalog.pg_constraint
+  WHERE contype = 'f' AND NOT condeferrable AND conrelid = ANY($1::text[]::regclass[])`, tableNames); err != nil {
+		return false, fmt.Errorf("unable to retrieve deferrable constraints: %w", err)
+	}
+
+	return count == 0, nil
+}
+
+// canSetReplicationRole returns true when the user is allowed to set session_replication_role.
+func (d *PGDialect) canSetReplicationRole(ctx context.Context) bool {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return false
+	}
+	//nolint:errcheck
+	defer tx.Rollback(ctx)
+
+	return d.exec(ctx, "SET LOCAL session_replication_role = replica") == nil
+}
+
+// relaxConstraints runs f with triggers and foreign keys relaxed according to the load mode,
+// it must be called within a transaction.
+func (d *PGDialect) relaxConstraints(ctx context.Context, mode dialect.LoadMode, table dialect.Table, f func(ctx context.Context) error) error {
+	switch mode {
+	case dialect.LoadModeDisableTriggers:
+		return d.disableTriggers(ctx, table, f)
+	case dialect.LoadModeReplica:
+		if err := d.exec(ctx, "SET LOCAL session_replication_role = replica"); err != nil {
+			return fmt.Errorf("unable to set replication role: %w", err)
+		}
+	case dialect.LoadModeDeferred:
+		if err := d.exec(ctx, "SET CONSTRAINTS ALL DEFERRED"); err != nil {
+			return fmt.Errorf("unable to defer constraints: %w", err)
+		}
+	}
+
+	return f(ctx)
 }
 
 func (d *PGDialect) disableTriggers(ctx context.Context, table dialect.Table, f func(ctx context.Context) error) error {
-	if err := d.exec(ctx, fmt.Sprintf("ALTER TABLE %s DISABLE TRIGGER ALL;", table.Name)); err != nil {
+	relation := "TABLE"
+	if table.Kind == dialect.TableKindForeign {
+		relation = "FOREIGN TABLE"
+	}
+
+	if err := d.exec(ctx, fmt.Sprintf("ALTER %s %s DISABLE TRIGGER ALL;", relation, table.Name)); err != nil {
 		return err
 	}
 
@@ -489,37 +1200,93 @@ func (d *PGDialect) disableTriggers(ctx context.Context, table dialect.Table, f
 		return err
 	}
 
-	if err := d.exec(ctx, fmt.Sprintf("ALTER TABLE %s ENABLE TRIGGER ALL;", table.Name)); err != nil {
+	if err := d.exec(ctx, fmt.Sprintf("ALTER %s %s ENABLE TRIGGER ALL;", relation, table.Name)); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *PGDialect) resetSequence(ctx context.Context, table dialect.Table) error {
-	tableSeqName := fmt.Sprintf("%s_id_seq", table.Name)
+// BindsArrays returns true since slices are encoded as PostgreSQL arrays.
+func (d *PGDialect) BindsArrays() bool {
+	return true
+}
 
-	var rawNextval interface{}
-	if err := d.queryRow(ctx, &rawNextval, fmt.Sprintf("SELECT nextval('%s')", tableSeqName)); err != nil {
-		return err
+// AllocateKeys returns count new values of the sequence owned by a column, values are allocated with
+// nextval and are never used by other sessions.
+func (d *PGDialect) AllocateKeys(ctx context.Context, table dialect.Table, columnName string, count int) ([]int64, error) {
+	sequence, ok := table.Sequence(columnName)
+	if !ok {
+		return nil, fmt.Errorf("unable to allocate keys of %s: column %s owns no sequence", table.Name, columnName)
 	}
-	var rawMaxval interface{}
-	if err := d.queryRow(ctx, &rawMaxval, fmt.Sprintf("SELECT MAX(%s) FROM %s", table.PrimaryKeyColumnName(), table.Name)); err != nil {
-		return err
+
+	var results []struct {
+		Value int64 `db:"value"`
+	}
+	if err := d.execQuery(ctx, &results, "SELECT nextval($1::regclass) AS value FROM generate_series(1, $2)",
+		quoteIdentifier(sequence.Name), count); err != nil {
+		return nil, fmt.Errorf("unable to allocate keys from sequence %s: %w", sequence.Name, err)
+	}
+
+	keys := make([]int64, len(results))
+	for i := range results {
+		keys[i] = results[i].Value
 	}
 
-	if rawMaxval != nil && rawNextval != nil {
-		nextval := interfaceToInt64(rawNextval)
+	return keys, nil
+}
+
+// resetSequences moves the sequences owned by the table columns past the largest loaded value,
+// sequences already ahead are left untouched.
+func (d *PGDialect) resetSequences(ctx context.Context, table dialect.Table) error {
+	for _, sequence := range table.Sequences {
+		var rawMaxval interface{}
+		if err := d.queryRow(ctx, &rawMaxval, fmt.Sprintf("SELECT MAX(%s) FROM %s",
+			quoteIdentifier(sequence.ColumnName), quoteIdentifier(table.Name))); err != nil {
+			return err
+		}
+
+		if rawMaxval == nil {
+			continue
+		}
+
+		var (
+			rawLastval interface{}
+			isCalled   bool
+		)
+		if err := d.db().QueryRow(ctx, fmt.Sprintf("SELECT last_value, is_called FROM %s",
+			quoteIdentifier(sequence.Name))).Scan(&rawLastval, &isCalled); err != nil {
+			return fmt.Errorf("unable to retrieve sequence %s state: %w", sequence.Name, err)
+		}
+
+		// The next value of a sequence which has never been called is its last value.
+		nextval := interfaceToInt64(rawLastval)
+		if isCalled {
+			nextval++
+		}
+
 		maxval := interfaceToInt64(rawMaxval)
+		if maxval < nextval {
+			continue
+		}
 
-		if maxval > nextval {
-			if err := d.exec(ctx, fmt.Sprintf("SELECT setval('%s', COALESCE((SELECT MAX(id)+1 FROM %s), 1), false);", tableSeqName, table.Name)); err != nil {
-				return err
-			}
+		if err := d.exec(ctx, "SELECT setval($1::regclass, $2, true)", quoteIdentifier(sequence.Name), maxval); err != nil {
+			return fmt.Errorf("unable to reset sequence %s: %w", sequence.Name, err)
 		}
 	}
 
 	return nil
 }
 
-var _ dialect.Dialect = (*PGDialect)(nil)
+var (
+	_ dialect.Dialect                   = (*PGDialect)(nil)
+	_ dialect.MaterializedViewRefresher = (*PGDialect)(nil)
+	_ dialect.SchemaFingerprinter       = (*PGDialect)(nil)
+	_ dialect.LoadSession               = (*PGDialect)(nil)
+	_ dialect.Updater                   = (*PGDialect)(nil)
+	_ dialect.KeyAllocator              = (*PGDialect)(nil)
+	_ dialect.Unloader                  = (*PGDialect)(nil)
+	_ dialect.IntegrityChecker          = (*PGDialect)(nil)
+	_ dialect.ArrayBinder               = (*PGDialect)(nil)
+	_ dialect.Snapshotter               = (*PGDialect)(nil)
+)
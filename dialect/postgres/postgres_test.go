@@ -0,0 +1,516 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// Integration tests run against the database of MOVER_POSTGRES_DSN with: go test -tags integration ./dialect/postgres/
+// Their tables are dropped and created again.
+const testSchema = `
+DROP SCHEMA IF EXISTS billing CASCADE;
+DROP TABLE IF EXISTS "user", project, log CASCADE;
+CREATE TABLE "user" (
+	id serial PRIMARY KEY,
+	username text NOT NULL UNIQUE,
+	profile jsonb,
+	settings json,
+	tags text[]
+);
+CREATE TABLE project (
+	id integer GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	name text NOT NULL,
+	user_id integer NOT NULL REFERENCES "user"(id)
+);
+CREATE TABLE log (
+	user_id integer,
+	action text NOT NULL,
+	payload json,
+	location point
+);
+CREATE SCHEMA billing;
+CREATE TABLE billing.invoice (
+	id bigserial PRIMARY KEY,
+	user_id integer REFERENCES public."user"(id),
+	amount integer NOT NULL
+);
+CREATE TABLE billing.line (
+	invoice_id bigint REFERENCES billing.invoice(id),
+	position integer,
+	label text NOT NULL,
+	PRIMARY KEY (invoice_id, position)
+);
+`
+
+func newTestDialect(t *testing.T) *PGDialect {
+	dsn := os.Getenv("MOVER_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("MOVER_POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+
+	d, err := NewPGDialect(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, d.Close(ctx))
+	})
+
+	pgDialect := d.(*PGDialect)
+	_, err = pgDialect.pool.Exec(ctx, testSchema)
+	require.NoError(t, err)
+
+	return pgDialect
+}
+
+// nextval returns the next value of a sequence.
+func nextval(t *testing.T, d *PGDialect, name string) int64 {
+	var value int64
+	require.NoError(t, d.pool.QueryRow(context.Background(), "SELECT nextval($1::regclass)", quoteIdentifier(name)).Scan(&value))
+
+	return value
+}
+
+func TestResetSequences(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, []dialect.Sequence{{Name: "user_id_seq", ColumnName: "id"}}, user.Sequences)
+
+	project, err := d.Table(ctx, "project")
+	require.NoError(t, err)
+	assert.Equal(t, []dialect.Sequence{{Name: "project_id_seq", ColumnName: "id"}}, project.Sequences)
+
+	invoice, err := d.Table(ctx, "billing.invoice")
+	require.NoError(t, err)
+	assert.Equal(t, []dialect.Sequence{{Name: "billing.invoice_id_seq", ColumnName: "id"}}, invoice.Sequences)
+
+	// The sequences of empty tables are left untouched.
+	require.NoError(t, d.resetSequences(ctx, user))
+
+	var (
+		lastValue int64
+		isCalled  bool
+	)
+	require.NoError(t, d.pool.QueryRow(ctx, `SELECT last_value, is_called FROM "user_id_seq"`).Scan(&lastValue, &isCalled))
+	assert.Equal(t, int64(1), lastValue)
+	assert.False(t, isCalled)
+
+	// The first value of a sequence which has never been called is its last value, it is moved
+	// past the loaded key even though the loaded key equals its last value.
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(1), "username": "thoas"},
+	}, dialect.InsertOptions{}))
+	assert.Equal(t, int64(2), nextval(t, d, "user_id_seq"))
+
+	// Sequences behind the loaded keys are moved past the largest one.
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(10), "username": "ulule"},
+		{"id": float64(5), "username": "mover"},
+	}, dialect.InsertOptions{}))
+	assert.Equal(t, int64(11), nextval(t, d, "user_id_seq"))
+
+	// Sequences ahead of the loaded keys are left untouched.
+	_, err = d.pool.Exec(ctx, `SELECT setval('project_id_seq', 100)`)
+	require.NoError(t, err)
+
+	require.NoError(t, d.BulkInsert(ctx, project, []map[string]interface{}{
+		{"id": float64(5), "name": "mover", "user_id": float64(1)},
+	}, dialect.InsertOptions{}))
+	assert.Equal(t, int64(101), nextval(t, d, "project_id_seq"))
+
+	// Sequences of other schemas are qualified.
+	require.NoError(t, d.BulkInsert(ctx, invoice, []map[string]interface{}{
+		{"id": float64(3), "user_id": float64(1), "amount": float64(100)},
+	}, dialect.InsertOptions{}))
+	assert.Equal(t, int64(4), nextval(t, d, "billing.invoice_id_seq"))
+}
+
+func TestTablesSchemas(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	// Only the public schema is introspected by default.
+	tables, err := d.Tables(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tables.Get("user").Name)
+	assert.Empty(t, tables.Get("billing.invoice").Name)
+
+	tables, err = d.Tables(ctx, "public", "billing")
+	require.NoError(t, err)
+
+	user := tables.Get("user")
+	assert.Equal(t, "", user.Schema)
+	assert.Equal(t, "user", user.Columns[0].TableName)
+
+	invoice := tables.Get("billing.invoice")
+	assert.Equal(t, "billing", invoice.Schema)
+	assert.Equal(t, dialect.TableKindTable, invoice.Kind)
+	assert.Equal(t, "billing.invoice", invoice.Columns[0].TableName)
+	assert.Equal(t, []string{"id"}, invoice.PrimaryKeyColumnNames())
+
+	// References across schemas are resolved to the qualified tables.
+	require.Len(t, invoice.ForeignKeys, 1)
+	assert.Equal(t, "user", invoice.ForeignKeys[0].ReferencedTableName)
+	assert.Equal(t, "user", invoice.ForeignKeys[0].ReferencedTable.Name)
+
+	referenceKeys := make([]string, len(user.ReferenceKeys))
+	for i := range user.ReferenceKeys {
+		referenceKeys[i] = user.ReferenceKeys[i].Table.Name
+	}
+	assert.ElementsMatch(t, []string{"project", "billing.invoice"}, referenceKeys)
+
+	require.Len(t, invoice.ReferenceKeys, 1)
+	assert.Equal(t, "billing.line", invoice.ReferenceKeys[0].TableName)
+	assert.Equal(t, "billing.line", invoice.ReferenceKeys[0].Table.Name)
+
+	line := tables.Get("billing.line")
+	assert.Equal(t, []string{"invoice_id", "position"}, line.PrimaryKeyColumnNames())
+	require.Len(t, line.ForeignKeys, 1)
+	assert.Equal(t, "billing.invoice", line.ForeignKeys[0].ReferencedTable.Name)
+	assert.Equal(t, dialect.ColumnReferences{{ColumnName: "invoice_id", ReferencedColumnName: "id"}}, line.ForeignKeys[0].Columns)
+
+	// Qualified tables are introspected, loaded and queried by their qualified name.
+	table, err := d.Table(ctx, "billing.line")
+	require.NoError(t, err)
+	assert.Equal(t, line.Columns, table.Columns)
+
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{{"id": 1, "username": "thoas"}}, dialect.InsertOptions{}))
+	require.NoError(t, d.BulkInsert(ctx, invoice, []map[string]interface{}{{"id": 1, "user_id": 1, "amount": 100}}, dialect.InsertOptions{}))
+	require.NoError(t, d.BulkInsert(ctx, line, []map[string]interface{}{{"invoice_id": 1, "position": 1, "label": "reward"}}, dialect.InsertOptions{}))
+
+	results, err := d.ResultSet(ctx, `SELECT * FROM "billing"."line" WHERE "invoice_id" = $1`, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "reward", results[0]["label"])
+}
+
+// testRows returns n rows built by row.
+func testRows(n int, row func(i int) map[string]interface{}) []map[string]interface{} {
+	data := make([]map[string]interface{}, n)
+	for i := range data {
+		data[i] = row(i)
+	}
+
+	return data
+}
+
+// TestBulkInsertConflictStrategies runs every conflict strategy with batches inserted row by row and
+// with batches copied through the staging table.
+func TestBulkInsertConflictStrategies(t *testing.T) {
+	ctx := context.Background()
+
+	for _, n := range []int{copyThreshold - 1, copyThreshold} {
+		d := newTestDialect(t)
+
+		count := func(tableName string) int {
+			var count int
+			require.NoError(t, d.pool.QueryRow(ctx, "SELECT COUNT(*) FROM "+quoteIdentifier(tableName)).Scan(&count))
+			return count
+		}
+
+		insert := func(table dialect.Table, data []map[string]interface{}, opts dialect.InsertOptions) ([][]interface{}, error) {
+			var keys [][]interface{}
+			opts.Inserted = func(key []interface{}) {
+				keys = append(keys, key)
+			}
+
+			return keys, d.BulkInsert(ctx, table, data, opts)
+		}
+
+		user, err := d.Table(ctx, "user")
+		require.NoError(t, err)
+
+		log, err := d.Table(ctx, "log")
+		require.NoError(t, err)
+
+		users := func(username string) []map[string]interface{} {
+			return testRows(n, func(i int) map[string]interface{} {
+				return map[string]interface{}{
+					"id":       float64(i + 1),
+					"username": fmt.Sprintf("%s-%d", username, i),
+					"profile":  map[string]interface{}{"lang": "fr"},
+					"settings": []interface{}{float64(i), "dark"},
+					"tags":     []interface{}{"go", `say "hi"`, nil},
+				}
+			})
+		}
+
+		// Every other row is a duplicate of the previous one.
+		logs := testRows(n, func(i int) map[string]interface{} {
+			return map[string]interface{}{
+				"user_id":  float64(i / 2),
+				"action":   "login",
+				"payload":  map[string]interface{}{"step": float64(i / 2)},
+				"location": "(1,2)",
+			}
+		})
+
+		// nothing
+		keys, err := insert(user, users("thoas"), dialect.InsertOptions{})
+		require.NoError(t, err, n)
+		assert.Len(t, keys, n, n)
+
+		data := append(users("ulule"), map[string]interface{}{
+			"id": float64(n + 1), "username": "new", "profile": nil, "settings": nil, "tags": nil,
+		})
+		keys, err = insert(user, data, dialect.InsertOptions{Conflict: dialect.ConflictNothing})
+		require.NoError(t, err, n)
+		assert.Equal(t, [][]interface{}{{float64(n + 1)}}, keys, n)
+		assert.Equal(t, n+1, count("user"), n)
+
+		results, err := d.ResultSet(ctx, `SELECT * FROM "user" WHERE "id" = $1`, 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "thoas-0", results[0]["username"], n)
+		assert.Equal(t, []interface{}{float64(0), "dark"}, results[0]["settings"], n)
+
+		var tags string
+		require.NoError(t, d.pool.QueryRow(ctx, `SELECT array_to_string(tags, ',', 'NULL') FROM "user" WHERE "id" = 1`).Scan(&tags))
+		assert.Equal(t, `go,say "hi",NULL`, tags, n)
+
+		// update, the last row of a key wins.
+		data = users("updated")
+		data[n-1] = users("updated")[0]
+		data[n-1]["username"] = "updated-last"
+		keys, err = insert(user, data, dialect.InsertOptions{Conflict: dialect.ConflictUpdate, UpdateColumns: []string{"username"}})
+		require.NoError(t, err, n)
+		assert.Empty(t, keys, n)
+
+		results, err = d.ResultSet(ctx, `SELECT * FROM "user" WHERE "id" = $1`, 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "updated-last", results[0]["username"], n)
+
+		// replace
+		keys, err = insert(user, testRows(n, func(i int) map[string]interface{} {
+			return map[string]interface{}{"id": float64(i + 1), "username": fmt.Sprintf("replaced-%d", i)}
+		}), dialect.InsertOptions{Conflict: dialect.ConflictReplace})
+		require.NoError(t, err, n)
+		assert.Empty(t, keys, n)
+		assert.Equal(t, n+1, count("user"), n)
+
+		results, err = d.ResultSet(ctx, `SELECT * FROM "user" WHERE "id" = $1`, 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "replaced-0", results[0]["username"], n)
+		assert.Nil(t, results[0]["profile"], n)
+
+		// fail
+		data = users("conflict")
+		data[0]["id"] = float64(n + 2)
+		_, err = insert(user, data, dialect.InsertOptions{Conflict: dialect.ConflictFail})
+
+		var conflictErr *dialect.ConflictError
+		require.ErrorAs(t, err, &conflictErr, n)
+		assert.Len(t, conflictErr.Keys, n-1, n)
+		assert.Equal(t, n+1, count("user"), n)
+
+		// skip_duplicates, json and point columns have no equality operator.
+		_, err = insert(log, logs, dialect.InsertOptions{Conflict: dialect.ConflictSkipDuplicates})
+		require.NoError(t, err, n)
+		assert.Equal(t, (n+1)/2, count("log"), n)
+
+		_, err = insert(log, logs, dialect.InsertOptions{Conflict: dialect.ConflictSkipDuplicates})
+		require.NoError(t, err, n)
+		assert.Equal(t, (n+1)/2, count("log"), n)
+
+		// append
+		_, err = insert(log, logs, dialect.InsertOptions{Conflict: dialect.ConflictAppend})
+		require.NoError(t, err, n)
+		assert.Equal(t, (n+1)/2+n, count("log"), n)
+	}
+}
+
+func TestDeferrable(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+
+	project, err := d.Table(ctx, "project")
+	require.NoError(t, err)
+
+	// Tables without foreign keys can be deferred.
+	deferrable, err := d.deferrable(ctx, dialect.Tables{user})
+	require.NoError(t, err)
+	assert.True(t, deferrable)
+
+	deferrable, err = d.deferrable(ctx, dialect.Tables{user, project})
+	require.NoError(t, err)
+	assert.False(t, deferrable)
+
+	_, err = d.pool.Exec(ctx, `ALTER TABLE project ALTER CONSTRAINT project_user_id_fkey DEFERRABLE`)
+	require.NoError(t, err)
+
+	deferrable, err = d.deferrable(ctx, dialect.Tables{user, project})
+	require.NoError(t, err)
+	assert.True(t, deferrable)
+
+	// Tables of other schemas are qualified.
+	line, err := d.Table(ctx, "billing.line")
+	require.NoError(t, err)
+
+	deferrable, err = d.deferrable(ctx, dialect.Tables{line})
+	require.NoError(t, err)
+	assert.False(t, deferrable)
+}
+
+func TestBulkInsertAutoModeWithoutSuperuser(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	_, err := d.pool.Exec(ctx, `DO $$ BEGIN CREATE ROLE mover_loader NOSUPERUSER; EXCEPTION WHEN duplicate_object THEN NULL; END $$;
+GRANT ALL ON ALL TABLES IN SCHEMA public TO mover_loader;
+GRANT ALL ON ALL SEQUENCES IN SCHEMA public TO mover_loader;`)
+	require.NoError(t, err)
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+
+	// The connection of the user is pinned as BulkInsert would, outside of a load.
+	d.conn, err = d.pool.Acquire(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := d.conn.Exec(ctx, "RESET ROLE")
+		assert.NoError(t, err)
+		d.release()
+	})
+
+	_, err = d.conn.Exec(ctx, "SET ROLE mover_loader")
+	require.NoError(t, err)
+
+	// Resolving the mode leaves the transaction of the batch intact: the batch is rolled back as a whole.
+	err = d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(1), "username": "thoas"},
+		{"id": float64(2), "username": nil},
+	}, dialect.InsertOptions{})
+
+	var rowErr *dialect.RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, 1, rowErr.Index)
+	assert.NotEqual(t, dialect.LoadModeDisableTriggers, d.mode)
+
+	var count int64
+	require.NoError(t, d.pool.QueryRow(ctx, `SELECT COUNT(*) FROM "user"`).Scan(&count))
+	assert.Equal(t, int64(0), count)
+
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(1), "username": "thoas"},
+	}, dialect.InsertOptions{}))
+
+	require.NoError(t, d.pool.QueryRow(ctx, `SELECT COUNT(*) FROM "user"`).Scan(&count))
+	assert.Equal(t, int64(1), count)
+}
+
+func TestLoadResetSequences(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+
+	_, err = d.BeginLoad(ctx, dialect.LoadOptions{Mode: dialect.LoadModeOrdered})
+	require.NoError(t, err)
+
+	// Every batch is committed and its table is reset once by EndLoad.
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(1), "username": "thoas"},
+	}, dialect.InsertOptions{}))
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(2), "username": "ulule"},
+	}, dialect.InsertOptions{}))
+	assert.Len(t, d.loaded, 1)
+
+	// The third batch fails, the rows of the previous ones are kept.
+	err = d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": float64(3), "username": nil},
+	}, dialect.InsertOptions{})
+	require.Error(t, err)
+	require.NoError(t, d.EndLoad(ctx, err))
+
+	var count int64
+	require.NoError(t, d.pool.QueryRow(ctx, `SELECT COUNT(*) FROM "user"`).Scan(&count))
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, int64(3), nextval(t, d, "user_id_seq"))
+
+	// Deferred constraints fail the commit, the sequences of the rolled back rows are left untouched.
+	_, err = d.pool.Exec(ctx, `ALTER TABLE project ALTER CONSTRAINT project_user_id_fkey DEFERRABLE`)
+	require.NoError(t, err)
+
+	project, err := d.Table(ctx, "project")
+	require.NoError(t, err)
+
+	_, err = d.BeginLoad(ctx, dialect.LoadOptions{Mode: dialect.LoadModeDeferred})
+	require.NoError(t, err)
+	require.NoError(t, d.BulkInsert(ctx, project, []map[string]interface{}{
+		{"id": float64(5), "name": "mover", "user_id": float64(99)},
+	}, dialect.InsertOptions{}))
+	require.Error(t, d.EndLoad(ctx, nil))
+
+	require.NoError(t, d.pool.QueryRow(ctx, `SELECT COUNT(*) FROM project`).Scan(&count))
+	assert.Equal(t, int64(0), count)
+	assert.Equal(t, int64(1), nextval(t, d, "project_id_seq"))
+}
+
+func TestSnapshot(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	// A connection of the pool is left to the queries which do not read the snapshot.
+	maxConns := int(d.pool.Config().MaxConns)
+	workers, err := d.BeginSnapshot(ctx, maxConns+1)
+	require.NoError(t, err)
+	if maxConns > 1 {
+		assert.Equal(t, maxConns-1, workers)
+	} else {
+		assert.Equal(t, 1, workers)
+	}
+
+	_, err = d.pool.Exec(ctx, `INSERT INTO "user" (username) VALUES ('thoas')`)
+	require.NoError(t, err)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	_, err = d.Tables(timeoutCtx)
+	require.NoError(t, err)
+
+	// Rows inserted after the snapshot are not read.
+	results, err := d.ResultSet(ctx, `SELECT * FROM "user"`)
+	require.NoError(t, err)
+	if maxConns > 1 {
+		assert.Empty(t, results)
+	}
+
+	require.NoError(t, d.EndSnapshot(ctx))
+
+	results, err = d.ResultSet(ctx, `SELECT * FROM "user"`)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// snapshot contains the read only transactions of the connections sharing a snapshot, idle
+// transactions are available from readers.
+type snapshot struct {
+	conns   []*pgxpool.Conn
+	txs     []pgx.Tx
+	readers chan pgx.Tx
+}
+
+// BeginSnapshot exports the snapshot of a repeatable read transaction and imports it in the transactions
+// of the other readers. Workers are bounded by the size of the pool minus one connection, left to the
+// statements which do not read the snapshot such as introspection queries. A pool of a single connection
+// reads without snapshot with a single worker.
+func (d *PGDialect) BeginSnapshot(ctx context.Context, workers int) (int, error) {
+	d.snapshotMu.Lock()
+	defer d.snapshotMu.Unlock()
+
+	if d.snapshot != nil {
+		return 0, fmt.Errorf("unable to begin snapshot: a snapshot has already begun")
+	}
+
+	if maxReaders := int(d.pool.Config().MaxConns) - 1; workers > maxReaders {
+		workers = maxReaders
+	}
+	if workers < 1 {
+		return 1, nil
+	}
+
+	s := &snapshot{readers: make(chan pgx.Tx, workers)}
+
+	var snapshotID string
+	for i := 0; i < workers; i++ {
+		conn, err := d.pool.Acquire(ctx)
+		if err != nil {
+			s.close(ctx)
+			return 0, fmt.Errorf("unable to acquire connection: %w", err)
+		}
+		s.conns = append(s.conns, conn)
+
+		tx, err := conn.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+		if err != nil {
+			s.close(ctx)
+			return 0, fmt.Errorf("unable to begin snapshot transaction: %w", err)
+		}
+		s.txs = append(s.txs, tx)
+
+		if i == 0 {
+			err = tx.QueryRow(ctx, "SELECT pg_export_snapshot()").Scan(&snapshotID)
+		} else {
+			_, err = tx.Exec(ctx, fmt.Sprintf("SET TRANSACTION SNAPSHOT '%s'", snapshotID))
+		}
+		if err != nil {
+			s.close(ctx)
+			return 0, fmt.Errorf("unable to share snapshot: %w", err)
+		}
+	}
+
+	for i := range s.txs {
+		s.readers <- s.txs[i]
+	}
+	d.snapshot = s
+
+	return workers, nil
+}
+
+// EndSnapshot rolls back the transactions of the snapshot and releases their connections once the
+// queries reading it are done.
+func (d *PGDialect) EndSnapshot(ctx context.Context) error {
+	d.snapshotMu.Lock()
+	s := d.snapshot
+	d.snapshot = nil
+	d.snapshotMu.Unlock()
+
+	if s == nil {
+		return nil
+	}
+
+	return s.close(ctx)
+}
+
+func (s *snapshot) close(ctx context.Context) error {
+	var err error
+	for i := range s.txs {
+		if rerr := s.txs[i].Rollback(ctx); rerr != nil && err == nil {
+			err = fmt.Errorf("unable to end snapshot: %w", rerr)
+		}
+	}
+
+	for i := range s.conns {
+		s.conns[i].Release()
+	}
+
+	return err
+}
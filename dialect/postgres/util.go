@@ -4,19 +4,333 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgtype"
 	"github.com/jackc/pgx/v4"
 	lk "github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/types"
 
 	"github.com/ulule/mover/dialect"
 )
 
+// qualifiedName returns the name of a table qualified by its schema unless it belongs to the default schema.
+func qualifiedName(schema, name string) string {
+	if schema == defaultSchema {
+		schema = ""
+	}
+
+	return dialect.QualifiedName(schema, name)
+}
+
+// quoteIdentifier quotes a table, sequence or column name, qualified names are quoted part by part.
+func quoteIdentifier(name string) string {
+	return pgx.Identifier(strings.Split(name, ".")).Sanitize()
+}
+
+func quoteIdentifiers(names []string) []string {
+	results := make([]string, len(names))
+	for i := range names {
+		results[i] = quoteIdentifier(names[i])
+	}
+
+	return results
+}
+
+// textComparedTypes are the types without a usable equality operator (box and circle compare their areas),
+// their values are compared as text.
+var textComparedTypes = map[string]struct{}{
+	"json":    {},
+	"xml":     {},
+	"point":   {},
+	"line":    {},
+	"lseg":    {},
+	"box":     {},
+	"path":    {},
+	"polygon": {},
+	"circle":  {},
+}
+
+// comparedAsText returns true when the values of a type, or of an array of this type, cannot be
+// compared with IS NOT DISTINCT FROM or DISTINCT.
+func comparedAsText(dataType string) bool {
+	_, ok := textComparedTypes[strings.TrimSuffix(dataType, "[]")]
+
+	return ok
+}
+
+// comparableExpression returns an expression of a value which can be compared, values of types without
+// equality operator are compared as text.
+func comparableExpression(expression, dataType string) string {
+	if comparedAsText(dataType) {
+		return "(" + expression + ")::text"
+	}
+
+	return expression
+}
+
+// keyPairs returns the pairs of the key columns of a row, false when the table has no key or
+// when a key value is NULL since such rows never conflict.
+func keyPairs(keyColumnNames []string, data map[string]interface{}) ([]interface{}, bool) {
+	if len(keyColumnNames) == 0 {
+		return nil, false
+	}
+
+	pairs := make([]interface{}, len(keyColumnNames))
+	for i := range keyColumnNames {
+		value := data[keyColumnNames[i]]
+		if value == nil {
+			return nil, false
+		}
+
+		pairs[i] = lk.Pair(keyColumnNames[i], value)
+	}
+
+	return pairs, true
+}
+
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func rowValues(row map[string]interface{}, columnNames []string) []interface{} {
+	values := make([]interface{}, len(columnNames))
+	for i := range columnNames {
+		values[i] = row[columnNames[i]]
+	}
+
+	return values
+}
+
+func pairsColumnNames(pairs []interface{}) []string {
+	names := make([]string, len(pairs))
+	for i := range pairs {
+		names[i] = pairs[i].(types.Pair).Key.(string)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func pairsValues(pairs []interface{}) []interface{} {
+	values := make([]interface{}, len(pairs))
+	for i := range pairs {
+		values[i] = pairs[i].(types.Pair).Value
+	}
+
+	return values
+}
+
+// copyColumns returns the sorted columns of rows which can be copied, rows are only copied
+// when they are numerous and all set the same columns of the table.
+func copyColumns(table dialect.Table, data []map[string]interface{}) ([]string, bool) {
+	if len(data) < copyThreshold {
+		return nil, false
+	}
+
+	columns := make([]string, 0, len(data[0]))
+	for k := range data[0] {
+		if table.Columns.Get(k).Name == "" {
+			return nil, false
+		}
+
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+
+	for i := range data {
+		if len(data[i]) != len(columns) {
+			return nil, false
+		}
+
+		for j := range columns {
+			if _, ok := data[i][columns[j]]; !ok {
+				return nil, false
+			}
+		}
+	}
+
+	return columns, true
+}
+
+// lastRowsByKey returns the rows without the rows followed by a row with the same values at the key indexes,
+// rows with a NULL key value are kept since they never conflict. Rows keep their order.
+func lastRowsByKey(rows [][]interface{}, keyIndexes []int) [][]interface{} {
+	var (
+		seen = make(map[string]struct{}, len(rows))
+		keep = make([]bool, len(rows))
+		key  = make([]interface{}, len(keyIndexes))
+		kept = 0
+	)
+	for i := len(rows) - 1; i >= 0; i-- {
+		null := false
+		for j := range keyIndexes {
+			key[j] = rows[i][keyIndexes[j]]
+			null = null || key[j] == nil
+		}
+
+		if !null {
+			k := fmt.Sprintf("%q", key)
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+		}
+
+		keep[i] = true
+		kept++
+	}
+
+	if kept == len(rows) {
+		return rows
+	}
+
+	results := make([][]interface{}, 0, kept)
+	for i := range rows {
+		if keep[i] {
+			results = append(results, rows[i])
+		}
+	}
+
+	return results
+}
+
+// textValues converts a row to the text representation of its values ordered by columns,
+// values are converted as they are for inserts.
+func textValues(ci *pgtype.ConnInfo, table dialect.Table, columns []string, data map[string]interface{}) ([]interface{}, error) {
+	pairs, err := valuesToPairs(table, data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert %v to pairs: %w", data, err)
+	}
+
+	values := make(map[string]interface{}, len(pairs))
+	for i := range pairs {
+		pair := pairs[i].(types.Pair)
+		values[pair.Key.(string)] = pair.Value
+	}
+
+	row := make([]interface{}, len(columns))
+	for i := range columns {
+		value, err := textValue(ci, table.Columns.Get(columns[i]).DataType, values[columns[i]])
+		if err != nil {
+			return nil, err
+		}
+
+		row[i] = value
+	}
+
+	return row, nil
+}
+
+// textValue returns the text representation of a value converted by valuesToPairs, nil for NULL.
+// Slices which are not converted to a pgtype array are encoded as array literals for array columns
+// and in JSON for other columns.
+func textValue(ci *pgtype.ConnInfo, dataType string, value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case pgtype.TextEncoder:
+		buf, err := value.EncodeText(ci, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode %v to text: %w", value, err)
+		}
+
+		if buf == nil {
+			return nil, nil
+		}
+
+		return string(buf), nil
+	case []interface{}:
+		if strings.HasSuffix(dataType, "[]") {
+			return arrayLiteral(value)
+		}
+
+		return jsonText(value)
+	case map[string]interface{}:
+		return jsonText(value)
+	}
+
+	return scalarText(value), nil
+}
+
+// arrayLiteral encodes values as a PostgreSQL array literal, nested slices are encoded as
+// nested arrays and other elements are quoted.
+func arrayLiteral(values []interface{}) (string, error) {
+	var b strings.Builder
+
+	b.WriteByte('{')
+	for i := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		switch value := values[i].(type) {
+		case nil:
+			b.WriteString("NULL")
+		case []interface{}:
+			nested, err := arrayLiteral(value)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(nested)
+		case map[string]interface{}:
+			text, err := jsonText(value)
+			if err != nil {
+				return "", err
+			}
+
+			b.WriteString(quoteArrayElement(text))
+		default:
+			b.WriteString(quoteArrayElement(scalarText(value)))
+		}
+	}
+	b.WriteByte('}')
+
+	return b.String(), nil
+}
+
+func quoteArrayElement(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+func jsonText(value interface{}) (string, error) {
+	res, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode %v to JSON: %w", value, err)
+	}
+
+	return string(res), nil
+}
+
+func scalarText(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+
+	return fmt.Sprint(value)
+}
+
 func interfaceToInt64(raw interface{}) int64 {
 	var val int64
 
 	switch raw := raw.(type) {
+	case int16:
+		val = int64(raw)
 	case int32:
 		val = int64(raw)
 	case int64:
@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jackc/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	lk "github.com/ulule/loukoum/v3"
+
+	"github.com/ulule/mover/dialect"
+)
+
+var testTable = dialect.Table{
+	Name: "project",
+	Columns: dialect.Columns{
+		{Name: "id", DataType: "integer"},
+		{Name: "name", DataType: "text"},
+		{Name: "goal", DataType: "numeric"},
+		{Name: "online", DataType: "boolean"},
+		{Name: "tags", DataType: "text[]"},
+		{Name: "rewards", DataType: "integer[]"},
+		{Name: "matrix", DataType: "bigint[]"},
+		{Name: "settings", DataType: "json"},
+		{Name: "profile", DataType: "jsonb"},
+		{Name: "history", DataType: "jsonb[]"},
+	},
+}
+
+func TestCopyColumns(t *testing.T) {
+	rows := func(n int, row map[string]interface{}) []map[string]interface{} {
+		data := make([]map[string]interface{}, n)
+		for i := range data {
+			data[i] = row
+		}
+
+		return data
+	}
+
+	columns, ok := copyColumns(testTable, rows(copyThreshold, map[string]interface{}{"name": "mover", "id": 1}))
+	require.True(t, ok)
+	assert.Equal(t, []string{"id", "name"}, columns)
+
+	// Few rows are inserted.
+	_, ok = copyColumns(testTable, rows(copyThreshold-1, map[string]interface{}{"id": 1}))
+	assert.False(t, ok)
+
+	// Rows with unknown columns are inserted to report the failing row.
+	_, ok = copyColumns(testTable, rows(copyThreshold, map[string]interface{}{"id": 1, "unknown": 1}))
+	assert.False(t, ok)
+
+	// Rows setting different columns are inserted.
+	data := rows(copyThreshold, map[string]interface{}{"id": 1, "name": "mover"})
+	data[copyThreshold-1] = map[string]interface{}{"id": 2, "goal": 100}
+	_, ok = copyColumns(testTable, data)
+	assert.False(t, ok)
+
+	data[copyThreshold-1] = map[string]interface{}{"id": 2}
+	_, ok = copyColumns(testTable, data)
+	assert.False(t, ok)
+}
+
+func TestTextValues(t *testing.T) {
+	columns := []string{"goal", "history", "id", "matrix", "name", "online", "profile", "rewards", "settings", "tags"}
+
+	row, err := textValues(pgtype.NewConnInfo(), testTable, columns, map[string]interface{}{
+		"id":       float64(1),
+		"name":     "mover",
+		"goal":     1000.5,
+		"online":   true,
+		"tags":     []interface{}{"go", `say "hi"`, `back\slash`, nil},
+		"rewards":  []interface{}{float64(1), float64(2)},
+		"matrix":   []interface{}{[]interface{}{float64(1), float64(2)}, []interface{}{float64(3), float64(4)}},
+		"settings": []interface{}{float64(1), "two"},
+		"profile":  map[string]interface{}{"lang": "fr"},
+		"history":  []interface{}{map[string]interface{}{"step": float64(1)}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		"1000.5",
+		`{"{\"step\":1}"}`,
+		"1",
+		`{{"1","2"},{"3","4"}}`,
+		"mover",
+		"true",
+		`{"lang":"fr"}`,
+		"{1,2}",
+		`[1,"two"]`,
+		`{"go","say \"hi\"","back\\slash",NULL}`,
+	}, row)
+
+	row, err = textValues(pgtype.NewConnInfo(), testTable, []string{"name", "settings"}, map[string]interface{}{
+		"name":     nil,
+		"settings": map[string]interface{}{"theme": "dark"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{nil, `{"theme":"dark"}`}, row)
+}
+
+func TestComparableExpression(t *testing.T) {
+	assert.Equal(t, `t."id"`, comparableExpression(`t."id"`, "integer"))
+	assert.Equal(t, `s."profile"::jsonb`, comparableExpression(`s."profile"::jsonb`, "jsonb"))
+	assert.Equal(t, `(s."settings"::json)::text`, comparableExpression(`s."settings"::json`, "json"))
+	assert.Equal(t, `(t."history")::text`, comparableExpression(`t."history"`, "json[]"))
+	assert.Equal(t, `(t."location")::text`, comparableExpression(`t."location"`, "point"))
+}
+
+func TestArrayLiteral(t *testing.T) {
+	literal, err := arrayLiteral([]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "{}", literal)
+
+	literal, err = arrayLiteral([]interface{}{float64(1.5), true, "a,b", `say "hi"`, `back\slash`, nil})
+	require.NoError(t, err)
+	assert.Equal(t, `{"1.5","true","a,b","say \"hi\"","back\\slash",NULL}`, literal)
+
+	literal, err = arrayLiteral([]interface{}{[]interface{}{"a", nil}, []interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, `{{"a",NULL},{}}`, literal)
+
+	literal, err = arrayLiteral([]interface{}{map[string]interface{}{"lang": "fr"}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"{\"lang\":\"fr\"}"}`, literal)
+}
+
+func TestComparedAsText(t *testing.T) {
+	for _, dataType := range []string{"json", "json[]", "xml", "point", "line", "lseg", "box", "path", "polygon", "circle[]"} {
+		assert.True(t, comparedAsText(dataType), dataType)
+	}
+
+	for _, dataType := range []string{"jsonb", "jsonb[]", "text", "integer[]", "numeric", "uuid"} {
+		assert.False(t, comparedAsText(dataType), dataType)
+	}
+}
+
+func TestKeyPairs(t *testing.T) {
+	pairs, ok := keyPairs([]string{"project_id", "position"}, map[string]interface{}{
+		"project_id": float64(1),
+		"position":   float64(2),
+		"label":      "reward",
+	})
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{lk.Pair("project_id", float64(1)), lk.Pair("position", float64(2))}, pairs)
+
+	// Tables without key and rows with a NULL key value never conflict.
+	_, ok = keyPairs(nil, map[string]interface{}{"label": "reward"})
+	assert.False(t, ok)
+
+	_, ok = keyPairs([]string{"project_id", "position"}, map[string]interface{}{"project_id": float64(1), "position": nil})
+	assert.False(t, ok)
+
+	_, ok = keyPairs([]string{"id"}, map[string]interface{}{"label": "reward"})
+	assert.False(t, ok)
+}
+
+func TestQualifiedName(t *testing.T) {
+	assert.Equal(t, "user_id_seq", qualifiedName(defaultSchema, "user_id_seq"))
+	assert.Equal(t, "billing.invoice_id_seq", qualifiedName("billing", "invoice_id_seq"))
+}
+
+func TestLastRowsByKey(t *testing.T) {
+	rows := [][]interface{}{
+		{"1", "a", "first"},
+		{"2", "a", "second"},
+		{"1", "a", "third"},
+		{nil, "a", "fourth"},
+		{nil, "a", "fifth"},
+		{"1", "b", "sixth"},
+	}
+
+	// The last row of a key wins, rows with a NULL key value are kept.
+	assert.Equal(t, [][]interface{}{
+		{"2", "a", "second"},
+		{"1", "a", "third"},
+		{nil, "a", "fourth"},
+		{nil, "a", "fifth"},
+		{"1", "b", "sixth"},
+	}, lastRowsByKey(rows, []int{0, 1}))
+
+	assert.Equal(t, [][]interface{}{
+		{"2", "a", "second"},
+		{nil, "a", "fourth"},
+		{nil, "a", "fifth"},
+		{"1", "b", "sixth"},
+	}, lastRowsByKey(rows, []int{0}))
+
+	assert.Equal(t, rows[:2], lastRowsByKey(rows[:2], []int{0}))
+}
@@ -0,0 +1,116 @@
+package dialect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultDialect is the dialect used for DSNs without scheme (e.g. "host=localhost dbname=ulule").
+const DefaultDialect = "postgres"
+
+// Opener initializes a Dialect from a DSN.
+type Opener func(ctx context.Context, dsn string) (Dialect, error)
+
+var (
+	registryMu sync.RWMutex
+	openers    = make(map[string]Opener)
+	schemes    = make(map[string]string)
+)
+
+// Register makes a dialect available by its name and by the DSN schemes it handles.
+// It panics if the name or one of the schemes is already registered.
+func Register(name string, opener Opener, dsnSchemes ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if opener == nil {
+		panic("dialect: Register opener is nil")
+	}
+
+	if _, ok := openers[name]; ok {
+		panic("dialect: Register called twice for dialect " + name)
+	}
+
+	for _, scheme := range dsnSchemes {
+		if _, ok := schemes[scheme]; ok {
+			panic("dialect: Register called twice for scheme " + scheme)
+		}
+	}
+
+	openers[name] = opener
+	for _, scheme := range dsnSchemes {
+		schemes[scheme] = name
+	}
+}
+
+// unregister removes a dialect and its DSN schemes, registered dialects are only removed by tests.
+func unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	delete(openers, name)
+	for scheme := range schemes {
+		if schemes[scheme] == name {
+			delete(schemes, scheme)
+		}
+	}
+}
+
+// Dialects returns a sorted list of the names of the registered dialects.
+func Dialects() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(openers))
+	for name := range openers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Resolve returns the name of the dialect handling a DSN from its scheme.
+func Resolve(dsn string) (string, error) {
+	idx := strings.Index(dsn, "://")
+	if idx == -1 {
+		return DefaultDialect, nil
+	}
+
+	scheme := dsn[:idx]
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	name, ok := schemes[scheme]
+	if !ok {
+		return "", fmt.Errorf("no dialect registered for scheme %s", scheme)
+	}
+
+	return name, nil
+}
+
+// Open initializes a registered dialect from its name. When name is empty, the dialect is resolved
+// from the DSN scheme.
+func Open(ctx context.Context, name, dsn string) (Dialect, error) {
+	if name == "" {
+		var err error
+		name, err = Resolve(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	registryMu.RLock()
+	opener, ok := openers[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown dialect %s (forgotten import?)", name)
+	}
+
+	return opener(ctx, dsn)
+}
@@ -0,0 +1,50 @@
+package dialect
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	var opened string
+
+	Register("registry_test", func(ctx context.Context, dsn string) (Dialect, error) {
+		opened = dsn
+		return nil, nil
+	}, "registrytest")
+	t.Cleanup(func() {
+		unregister("registry_test")
+	})
+
+	assert.Contains(t, Dialects(), "registry_test")
+
+	name, err := Resolve("registrytest://localhost/db")
+	assert.NoError(t, err)
+	assert.Equal(t, "registry_test", name)
+
+	name, err = Resolve("host=localhost dbname=ulule")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultDialect, name)
+
+	_, err = Resolve("unknown://localhost/db")
+	assert.Error(t, err)
+
+	_, err = Open(context.Background(), "", "registrytest://localhost/db")
+	assert.NoError(t, err)
+	assert.Equal(t, "registrytest://localhost/db", opened)
+
+	_, err = Open(context.Background(), "registry_test", "file.db")
+	assert.NoError(t, err)
+	assert.Equal(t, "file.db", opened)
+
+	_, err = Open(context.Background(), "unknown", "file.db")
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		Register("registry_test", func(ctx context.Context, dsn string) (Dialect, error) {
+			return nil, nil
+		})
+	})
+}
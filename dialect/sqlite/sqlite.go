@@ -0,0 +1,803 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	// Register the pure Go SQLite driver.
+	_ "modernc.org/sqlite"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// defaultSchema is the schema of tables which are not qualified.
+const defaultSchema = "main"
+
+// tableKinds maps sqlite_master.type to table kinds.
+var tableKinds = map[string]dialect.TableKind{
+	"table": dialect.TableKindTable,
+	"view":  dialect.TableKindView,
+}
+
+func init() {
+	dialect.Register("sqlite", NewSQLiteDialect, "sqlite", "sqlite3")
+}
+
+// NewSQLiteDialect initializes a new SQLiteDialect instance.
+func NewSQLiteDialect(ctx context.Context, dsn string) (dialect.Dialect, error) {
+	db, err := sql.Open("sqlite", parseDSN(dsn))
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to database with dsn %s: %w", dsn, err)
+	}
+
+	// SQLite does not handle concurrent writers and pragmas are scoped to a connection.
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("unable to connect to database with dsn %s: %w", dsn, err)
+	}
+
+	return &SQLiteDialect{
+		db:   db,
+		path: parseDSN(dsn),
+	}, nil
+}
+
+// SQLiteDialect manages a connection with SQLite.
+//
+// SQLite schemas are attached databases, tables of the main database are not qualified.
+type SQLiteDialect struct {
+	db   *sql.DB
+	path string
+}
+
+// Close closes a connection.
+func (d *SQLiteDialect) Close(ctx context.Context) error {
+	return d.db.Close()
+}
+
+// ResultSet executes a query and converts Rows in map[string]interface{}.
+func (d *SQLiteDialect) ResultSet(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := d.db.QueryContext(ctx, rewritePlaceholders(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve results : %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		result, err := marshalRows(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve results : %w", err)
+	}
+
+	return results, nil
+}
+
+// DanglingReferences returns the number of rows of a table referencing rows which do not exist through
+// a foreign key, and at most limit of these rows.
+func (d *SQLiteDialect) DanglingReferences(ctx context.Context, table dialect.Table, foreignKey dialect.ForeignKey, limit int) (int64, []map[string]interface{}, error) {
+	clause := dialect.DanglingReferencesClause(table, foreignKey, quoteIdentifier)
+
+	var count int64
+	if err := d.db.QueryRowContext(ctx, "SELECT COUNT(*) "+clause).Scan(&count); err != nil {
+		return 0, nil, fmt.Errorf("unable to count dangling references of %s: %w", table.Name, err)
+	}
+
+	if count == 0 || limit == 0 {
+		return count, nil, nil
+	}
+
+	rows, err := d.ResultSet(ctx, fmt.Sprintf("SELECT c.* %s LIMIT %d", clause, limit))
+	if err != nil {
+		return 0, nil, fmt.Errorf("unable to retrieve dangling references of %s: %w", table.Name, err)
+	}
+
+	return count, rows, nil
+}
+
+// BulkInsert inserts multiple data a single database transaction. It disables foreign keys enforcement
+// to avoid conflicts on foreign constraints.
+//
+// SQLite keeps rowid and AUTOINCREMENT counters above the largest inserted key so there is
+// no sequence to reset afterwards.
+func (d *SQLiteDialect) BulkInsert(ctx context.Context, table dialect.Table, data []map[string]interface{}, opts dialect.InsertOptions) error {
+	if !table.Insertable() {
+		return fmt.Errorf("unable to insert to %s: %s is not insertable", table.Name, table.Kind)
+	}
+
+	return d.disableForeignKeys(ctx, func(ctx context.Context) error {
+		tx, err := d.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("unable to begin transaction on table %s: %w", table.Name, err)
+		}
+
+		if opts.ConflictStrategy() == dialect.ConflictFail {
+			if err := d.conflicts(ctx, tx, table, data); err != nil {
+				_ = tx.Rollback()
+				return err
+			}
+		}
+
+		for i := range data {
+			if err := d.insert(ctx, tx, table, data[i], opts); err != nil {
+				_ = tx.Rollback()
+				return &dialect.RowError{TableName: table.Name, Index: i, Row: data[i], Err: err}
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("unable to commit transaction on table %s: %w", table.Name, err)
+		}
+
+		return nil
+	})
+}
+
+// ReferenceKeys returns the "Referenced by" constraints of a table.
+func (d *SQLiteDialect) ReferenceKeys(ctx context.Context, tableName string) (dialect.ReferenceKeys, error) {
+	schema, name := splitName(tableName)
+	query := fmt.Sprintf(`SELECT m.name, f.id, f."from", f."to"
+FROM %s.sqlite_master m
+JOIN pragma_foreign_key_list(m.name, ?) f
+WHERE m.type = 'table' AND f."table" = ?
+ORDER BY m.name, f.id, f.seq`, quoteIdentifier(schema))
+
+	rows, err := d.db.QueryContext(ctx, query, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s reference keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	var (
+		referenceKeys     = make(dialect.ReferenceKeys, 0)
+		columnNames       = make(map[string][]string)
+		referencedColumns = make(map[string][]string)
+		names             = make([]string, 0)
+		tableNames        = make(map[string]string)
+	)
+	for rows.Next() {
+		var (
+			table                string
+			id                   int64
+			columnName           string
+			referencedColumnName sql.NullString
+		)
+		if err := rows.Scan(&table, &id, &columnName, &referencedColumnName); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s reference keys: %w", tableName, err)
+		}
+
+		key := fmt.Sprintf("%s.%d", table, id)
+		if _, ok := columnNames[key]; !ok {
+			names = append(names, key)
+			tableNames[key] = table
+		}
+		columnNames[key] = append(columnNames[key], columnName)
+		referencedColumns[key] = append(referencedColumns[key], referencedColumnName.String)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s reference keys: %w", tableName, err)
+	}
+
+	for _, key := range names {
+		// A foreign key without target columns references the primary key of the table.
+		if referencedColumns[key][0] == "" {
+			primaryKeys, err := d.PrimaryKeys(ctx, tableName)
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range primaryKeys {
+				if i < len(referencedColumns[key]) {
+					referencedColumns[key][i] = primaryKeys[i].Name
+				}
+			}
+		}
+
+		referenceKeys = append(referenceKeys, dialect.ReferenceKey{
+			Name:      foreignKeyName(tableNames[key], columnNames[key]),
+			TableName: qualifiedName(schema, tableNames[key]),
+			Columns:   columnReferences(columnNames[key], referencedColumns[key]),
+		})
+	}
+
+	return referenceKeys, nil
+}
+
+// ForeignKeys returns the foreign keys of a table.
+//
+// SQLite constraints are anonymous, names follow the PostgreSQL convention <table>_<columns>_fkey.
+func (d *SQLiteDialect) ForeignKeys(ctx context.Context, tableName string) (dialect.ForeignKeys, error) {
+	query := `SELECT id, "table", "from", "to" FROM pragma_foreign_key_list(?, ?) ORDER BY id, seq`
+
+	schema, name := splitName(tableName)
+	rows, err := d.db.QueryContext(ctx, query, name, schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s foreign keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	var (
+		foreignKeys       = make(dialect.ForeignKeys, 0)
+		columnNames       = make(map[int64][]string)
+		referencedColumns = make(map[int64][]string)
+		ids               = make([]int64, 0)
+		referencedTables  = make(map[int64]string)
+	)
+	for rows.Next() {
+		var (
+			id                   int64
+			referencedTableName  string
+			columnName           string
+			referencedColumnName sql.NullString
+		)
+		if err := rows.Scan(&id, &referencedTableName, &columnName, &referencedColumnName); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s foreign keys: %w", tableName, err)
+		}
+
+		if _, ok := columnNames[id]; !ok {
+			ids = append(ids, id)
+			// Foreign keys cannot reference a table from another database.
+			referencedTables[id] = qualifiedName(schema, referencedTableName)
+		}
+		columnNames[id] = append(columnNames[id], columnName)
+		referencedColumns[id] = append(referencedColumns[id], referencedColumnName.String)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s foreign keys: %w", tableName, err)
+	}
+
+	for _, id := range ids {
+		referencedTableName := referencedTables[id]
+
+		// A foreign key without target columns references the primary key of the parent table.
+		if referencedColumns[id][0] == "" {
+			primaryKeys, err := d.PrimaryKeys(ctx, referencedTableName)
+			if err != nil {
+				return nil, err
+			}
+
+			for i := range primaryKeys {
+				if i < len(referencedColumns[id]) {
+					referencedColumns[id][i] = primaryKeys[i].Name
+				}
+			}
+		}
+
+		foreignKey := dialect.ForeignKey{
+			Name:                foreignKeyName(name, columnNames[id]),
+			Columns:             columnReferences(columnNames[id], referencedColumns[id]),
+			ReferencedTableName: referencedTableName,
+		}
+		foreignKey.Definition = fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)",
+			strings.Join(columnNames[id], ", "), foreignKey.ReferencedTableName, strings.Join(referencedColumns[id], ", "))
+
+		foreignKeys = append(foreignKeys, foreignKey)
+	}
+
+	return foreignKeys, nil
+}
+
+// PrimaryKeyConstraint returns the primary key constraint of a table.
+//
+// SQLite constraints are anonymous, the name follows the PostgreSQL convention <table>_pkey.
+func (d *SQLiteDialect) PrimaryKeyConstraint(ctx context.Context, tableName string) (string, error) {
+	primaryKeys, err := d.PrimaryKeys(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+
+	if len(primaryKeys) == 0 {
+		return "", fmt.Errorf("unable to retrieve table %s primary key constraint: %w", tableName, sql.ErrNoRows)
+	}
+
+	_, name := splitName(tableName)
+
+	return name + "_pkey", nil
+}
+
+// PrimaryKeys returns primary keys of a table.
+func (d *SQLiteDialect) PrimaryKeys(ctx context.Context, tableName string) ([]dialect.PrimaryKey, error) {
+	query := `SELECT name, type FROM pragma_table_info(?, ?) WHERE pk > 0 ORDER BY pk`
+
+	schema, name := splitName(tableName)
+	rows, err := d.db.QueryContext(ctx, query, name, schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s primary keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	primaryKeys := make([]dialect.PrimaryKey, 0)
+	for rows.Next() {
+		primaryKey := dialect.PrimaryKey{TableName: tableName}
+		if err := rows.Scan(&primaryKey.Name, &primaryKey.DataType); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s primary keys: %w", tableName, err)
+		}
+
+		primaryKeys = append(primaryKeys, primaryKey)
+	}
+
+	return primaryKeys, rows.Err()
+}
+
+// UniqueKeys returns the unique constraints and indexes of a table which are not the primary key,
+// partial and expression indexes are ignored since they do not identify rows.
+func (d *SQLiteDialect) UniqueKeys(ctx context.Context, tableName string) ([]dialect.UniqueKey, error) {
+	query := `SELECT l.name, i.name
+FROM pragma_index_list(?, ?) l
+JOIN pragma_index_info(l.name, ?) i
+WHERE l."unique" AND l.origin <> 'pk' AND NOT l.partial
+ORDER BY l.name, i.seqno`
+
+	schema, name := splitName(tableName)
+	rows, err := d.db.QueryContext(ctx, query, name, schema, schema)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s unique keys: %w", tableName, err)
+	}
+	defer rows.Close()
+
+	var (
+		uniqueKeys  = make([]dialect.UniqueKey, 0)
+		expressions = make(map[string]struct{})
+	)
+	for rows.Next() {
+		var (
+			name       string
+			columnName sql.NullString
+		)
+		if err := rows.Scan(&name, &columnName); err != nil {
+			return nil, fmt.Errorf("unable to retrieve table %s unique keys: %w", tableName, err)
+		}
+
+		// Expression columns have no name.
+		if !columnName.Valid {
+			expressions[name] = struct{}{}
+		}
+
+		last := len(uniqueKeys) - 1
+		if last >= 0 && uniqueKeys[last].Name == name {
+			uniqueKeys[last].ColumnNames = append(uniqueKeys[last].ColumnNames, columnName.String)
+			continue
+		}
+
+		uniqueKeys = append(uniqueKeys, dialect.UniqueKey{Name: name, ColumnNames: []string{columnName.String}})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to retrieve table %s unique keys: %w", tableName, err)
+	}
+
+	results := make([]dialect.UniqueKey, 0, len(uniqueKeys))
+	for i := range uniqueKeys {
+		if _, ok := expressions[uniqueKeys[i].Name]; !ok {
+			results = append(results, uniqueKeys[i])
+		}
+	}
+
+	return results, nil
+}
+
+// Columns returns sorted columns with types of a table.
+func (d *SQLiteDialect) Columns(ctx context.Context, tableName string) ([]dialect.Column, error) {
+	schema, name := splitName(tableName)
+
+	return d.columns(ctx, schema, name)
+}
+
+func (d *SQLiteDialect) columns(ctx context.Context, schema, tableName string) ([]dialect.Column, error) {
+	query := fmt.Sprintf(`SELECT p.name, p.type, NOT p."notnull", m.name, p.cid + 1
+FROM %s.sqlite_master m
+JOIN pragma_table_info(m.name, ?) p
+WHERE m.type IN ('table', 'view')`, quoteIdentifier(schema))
+	args := []interface{}{schema}
+
+	if tableName != "" {
+		query += " AND m.name = ?"
+		args = append(args, tableName)
+	}
+
+	query += " ORDER BY m.name, p.cid"
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+	}
+	defer rows.Close()
+
+	columns := make([]dialect.Column, 0)
+	for rows.Next() {
+		var column dialect.Column
+		if err := rows.Scan(&column.Name, &column.DataType, &column.Nullable, &column.TableName, &column.Position); err != nil {
+			return nil, fmt.Errorf("unable to execute query %s with args %v: %w", query, args, err)
+		}
+		column.TableName = qualifiedName(schema, column.TableName)
+
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+// Table returns a table with its reference keys, foreign keys, columns and primary keys.
+func (d *SQLiteDialect) Table(ctx context.Context, tableName string) (dialect.Table, error) {
+	columns, err := d.Columns(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	kind, err := d.tableKind(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	schema, _ := dialect.SplitName(tableName)
+	table := dialect.Table{
+		Name:    tableName,
+		Schema:  schema,
+		Kind:    kind,
+		Columns: columns,
+	}
+	table.ReferenceKeys, err = d.ReferenceKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	table.ForeignKeys, err = d.ForeignKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	table.PrimaryKeys, err = d.PrimaryKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	table.UniqueKeys, err = d.UniqueKeys(ctx, tableName)
+	if err != nil {
+		return dialect.Table{}, err
+	}
+
+	return table, nil
+}
+
+// Tables returns all the tables from the given schemas (attached databases), the main
+// database is used by default.
+func (d *SQLiteDialect) Tables(ctx context.Context, schemas ...string) (dialect.Tables, error) {
+	if len(schemas) == 0 {
+		schemas = []string{defaultSchema}
+	}
+
+	var (
+		tables  dialect.Tables
+		columns []dialect.Column
+	)
+	for _, schema := range schemas {
+		schemaTables, err := d.tables(ctx, schema)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, schemaTables...)
+
+		schemaColumns, err := d.columns(ctx, schema, "")
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve columns: %w", err)
+		}
+		columns = append(columns, schemaColumns...)
+	}
+
+	sortedColumns := make(map[string]dialect.Columns)
+	for i := range columns {
+		tableName := columns[i].TableName
+		sortedColumns[tableName] = append(sortedColumns[tableName], columns[i])
+	}
+
+	for i := range tables {
+		tableName := tables[i].Name
+		sort.Sort(sortedColumns[tableName])
+		tables[i].Schema, _ = dialect.SplitName(tableName)
+		tables[i].Columns = sortedColumns[tableName]
+
+		var err error
+		tables[i].ReferenceKeys, err = d.ReferenceKeys(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		tables[i].ForeignKeys, err = d.ForeignKeys(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		tables[i].PrimaryKeys, err = d.PrimaryKeys(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		tables[i].UniqueKeys, err = d.UniqueKeys(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tablesMap := make(map[string]dialect.Table, len(tables))
+	for i := range tables {
+		tablesMap[tables[i].Name] = tables[i]
+	}
+
+	for i := range tables {
+		for j := range tables[i].ReferenceKeys {
+			tables[i].ReferenceKeys[j].Table = tablesMap[tables[i].ReferenceKeys[j].TableName]
+		}
+
+		for j := range tables[i].ForeignKeys {
+			tables[i].ForeignKeys[j].ReferencedTable = tablesMap[tables[i].ForeignKeys[j].ReferencedTableName]
+		}
+	}
+
+	return tables, nil
+}
+
+func (d *SQLiteDialect) tableKind(ctx context.Context, tableName string) (dialect.TableKind, error) {
+	schema, name := splitName(tableName)
+	query := fmt.Sprintf(`SELECT type FROM %s.sqlite_master WHERE name = ?`, quoteIdentifier(schema))
+
+	var tableType string
+	if err := d.db.QueryRowContext(ctx, query, name).Scan(&tableType); err != nil {
+		return "", fmt.Errorf("unable to retrieve table %s kind: %w", tableName, err)
+	}
+
+	return tableKinds[tableType], nil
+}
+
+// Identity identifies the database file.
+func (d *SQLiteDialect) Identity() string {
+	return "sqlite://" + d.path
+}
+
+// SchemaFingerprint returns a hash of the definitions of the given schemas, SQLite keeps the
+// statements creating tables, views and indexes in sqlite_master.
+func (d *SQLiteDialect) SchemaFingerprint(ctx context.Context, schemas ...string) (string, error) {
+	if len(schemas) == 0 {
+		schemas = []string{defaultSchema}
+	}
+
+	hash := sha256.New()
+	for _, schema := range schemas {
+		query := fmt.Sprintf(`SELECT type, name, tbl_name, COALESCE(sql, '') FROM %s.sqlite_master ORDER BY type, name`,
+			quoteIdentifier(schema))
+
+		rows, err := d.db.QueryContext(ctx, query)
+		if err != nil {
+			return "", fmt.Errorf("unable to execute query %s: %w", query, err)
+		}
+
+		for rows.Next() {
+			var objectType, name, tableName, definition string
+			if err := rows.Scan(&objectType, &name, &tableName, &definition); err != nil {
+				rows.Close()
+				return "", fmt.Errorf("unable to execute query %s: %w", query, err)
+			}
+
+			fmt.Fprintf(hash, "%s\x00%s\x00%s\x00%s\x00%s\n", schema, objectType, name, tableName, definition)
+		}
+
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("unable to execute query %s: %w", query, err)
+		}
+		rows.Close()
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// tables returns the names and kinds of the tables of a schema.
+func (d *SQLiteDialect) tables(ctx context.Context, schema string) (dialect.Tables, error) {
+	query := fmt.Sprintf(`SELECT name, type FROM %s.sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%%' ORDER BY name`,
+		quoteIdentifier(schema))
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+	defer rows.Close()
+
+	var tables dialect.Tables
+	for rows.Next() {
+		var tableName, tableType string
+		if err := rows.Scan(&tableName, &tableType); err != nil {
+			return nil, fmt.Errorf("unable to execute query %s: %w", query, err)
+		}
+
+		tables = append(tables, dialect.Table{
+			Name: qualifiedName(schema, tableName),
+			Kind: tableKinds[tableType],
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+
+	return tables, nil
+}
+
+func (d *SQLiteDialect) insert(ctx context.Context, tx *sql.Tx, table dialect.Table, data map[string]interface{}, opts dialect.InsertOptions) error {
+	columns, args, err := valuesToArgs(data)
+	if err != nil {
+		return fmt.Errorf("unable to convert %v to arguments: %w", data, err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		quoteIdentifier(table.Name),
+		strings.Join(quoteIdentifiers(columns), ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+
+	switch opts.ConflictStrategy() {
+	case dialect.ConflictNothing:
+		// Without a primary key, rows conflicting on any unique constraint are skipped.
+		if len(table.PrimaryKeys) > 0 {
+			query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(quoteIdentifiers(table.PrimaryKeyColumnNames()), ", "))
+		} else {
+			query += " ON CONFLICT DO NOTHING"
+		}
+	case dialect.ConflictSkipDuplicates:
+		exists, err := d.exists(ctx, tx, table, columns, args)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return nil
+		}
+	case dialect.ConflictUpdate:
+		keyColumnNames := table.KeyColumnNames()
+		if len(keyColumnNames) == 0 {
+			break
+		}
+
+		updates := opts.UpdateColumnNames(table, columns)
+		if len(updates) == 0 {
+			query += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(quoteIdentifiers(keyColumnNames), ", "))
+			break
+		}
+
+		sets := make([]string, len(updates))
+		for i := range updates {
+			sets[i] = fmt.Sprintf("%s = excluded.%s", quoteIdentifier(updates[i]), quoteIdentifier(updates[i]))
+		}
+		query += fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+			strings.Join(quoteIdentifiers(keyColumnNames), ", "), strings.Join(sets, ", "))
+	case dialect.ConflictReplace:
+		if err := d.delete(ctx, tx, table, columns, args); err != nil {
+			return err
+		}
+	case dialect.ConflictAppend, dialect.ConflictFail:
+	default:
+		return fmt.Errorf("unable to insert to %s: %w", table.Name, opts.Conflict.Validate())
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("unable to insert %v+ to %s:%w", data, table.Name, err)
+	}
+
+	return nil
+}
+
+// delete deletes the row with the same key as the given values, rows with a NULL key conflict with no row.
+func (d *SQLiteDialect) delete(ctx context.Context, tx *sql.Tx, table dialect.Table, columns []string, args []interface{}) error {
+	keyColumnNames := table.KeyColumnNames()
+	keyArgs, ok := keyValues(keyColumnNames, columns, args)
+	if !ok {
+		return nil
+	}
+
+	conditions := make([]string, len(keyColumnNames))
+	for i := range keyColumnNames {
+		conditions[i] = quoteIdentifier(keyColumnNames[i]) + " = ?"
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", quoteIdentifier(table.Name), strings.Join(conditions, " AND "))
+	if _, err := tx.ExecContext(ctx, query, keyArgs...); err != nil {
+		return fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+
+	return nil
+}
+
+// conflicts returns a ConflictError reporting the rows conflicting on the key of the table,
+// nil when no row conflicts.
+func (d *SQLiteDialect) conflicts(ctx context.Context, tx *sql.Tx, table dialect.Table, data []map[string]interface{}) error {
+	keyColumnNames := table.KeyColumnNames()
+	keys := make([][]interface{}, 0)
+	for i := range data {
+		columns, args, err := valuesToArgs(data[i])
+		if err != nil {
+			return fmt.Errorf("unable to convert %v to arguments: %w", data[i], err)
+		}
+
+		keyArgs, ok := keyValues(keyColumnNames, columns, args)
+		if !ok {
+			continue
+		}
+
+		exists, err := d.exists(ctx, tx, table, keyColumnNames, keyArgs)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			keys = append(keys, keyArgs)
+		}
+	}
+
+	if len(keys) > 0 {
+		return &dialect.ConflictError{TableName: table.Name, ColumnNames: keyColumnNames, Keys: keys}
+	}
+
+	return nil
+}
+
+// exists returns true when a row of the table is identical to the given values, NULL values included.
+func (d *SQLiteDialect) exists(ctx context.Context, tx *sql.Tx, table dialect.Table, columns []string, args []interface{}) (bool, error) {
+	conditions := make([]string, len(columns))
+	for i := range columns {
+		conditions[i] = quoteIdentifier(columns[i]) + " IS ?"
+	}
+
+	query := fmt.Sprintf("SELECT 1 FROM %s WHERE %s LIMIT 1", quoteIdentifier(table.Name), strings.Join(conditions, " AND "))
+
+	var result int
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&result); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("unable to execute query %s: %w", query, err)
+	}
+
+	return true, nil
+}
+
+func (d *SQLiteDialect) disableForeignKeys(ctx context.Context, f func(ctx context.Context) error) error {
+	var enabled bool
+	if err := d.db.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+		return fmt.Errorf("unable to retrieve foreign keys enforcement: %w", err)
+	}
+
+	if !enabled {
+		return f(ctx)
+	}
+
+	if _, err := d.db.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("unable to disable foreign keys: %w", err)
+	}
+
+	err := f(ctx)
+
+	if _, cerr := d.db.ExecContext(ctx, "PRAGMA foreign_keys = ON"); cerr != nil && err == nil {
+		err = fmt.Errorf("unable to enable foreign keys: %w", cerr)
+	}
+
+	return err
+}
+
+var (
+	_ dialect.Dialect             = (*SQLiteDialect)(nil)
+	_ dialect.SchemaFingerprinter = (*SQLiteDialect)(nil)
+	_ dialect.IntegrityChecker    = (*SQLiteDialect)(nil)
+)
@@ -0,0 +1,364 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/dialect"
+)
+
+const testSchema = `
+CREATE TABLE user (
+	id INTEGER PRIMARY KEY,
+	username TEXT NOT NULL,
+	profile TEXT
+);
+CREATE TABLE project (
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	user_id INTEGER NOT NULL REFERENCES user(id)
+);
+CREATE TABLE backer (
+	user_id INTEGER NOT NULL,
+	project_id INTEGER NOT NULL,
+	PRIMARY KEY (user_id, project_id),
+	FOREIGN KEY (user_id) REFERENCES user,
+	FOREIGN KEY (project_id) REFERENCES project(id)
+);
+CREATE TABLE contribution (
+	id INTEGER PRIMARY KEY,
+	user_id INTEGER,
+	project_id INTEGER,
+	FOREIGN KEY (user_id, project_id) REFERENCES backer
+);
+CREATE TABLE log (
+	user_id INTEGER,
+	action TEXT NOT NULL
+);
+CREATE TABLE membership (
+	email TEXT,
+	role TEXT NOT NULL
+);
+CREATE UNIQUE INDEX membership_email_key ON membership (email);
+CREATE UNIQUE INDEX membership_lower_email_key ON membership (lower(email));
+CREATE VIEW project_owner AS SELECT p.id AS project_id, u.username FROM project p JOIN user u ON u.id = p.user_id;
+`
+
+func newTestDialect(t *testing.T) *SQLiteDialect {
+	ctx := context.Background()
+
+	d, err := NewSQLiteDialect(ctx, "sqlite://"+filepath.Join(t.TempDir(), "mover.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, d.Close(ctx))
+	})
+
+	sqliteDialect := d.(*SQLiteDialect)
+	_, err = sqliteDialect.db.ExecContext(ctx, testSchema)
+	require.NoError(t, err)
+
+	return sqliteDialect
+}
+
+func TestTables(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	tables, err := d.Tables(ctx)
+	require.NoError(t, err)
+	require.Len(t, tables, 7)
+
+	user := tables.Get("user")
+	assert.Equal(t, []string{"id"}, user.PrimaryKeyColumnNames())
+	assert.Equal(t, []string{"id", "username", "profile"}, columnNames(user.Columns))
+	assert.False(t, user.Columns.Get("username").Nullable)
+	assert.True(t, user.Columns.Get("profile").Nullable)
+	require.Len(t, user.ReferenceKeys, 2)
+	assert.Equal(t, "backer", user.ReferenceKeys[0].TableName)
+	assert.Equal(t, "backer", user.ReferenceKeys[0].Table.Name)
+	assert.Equal(t, dialect.ColumnReferences{{ColumnName: "user_id", ReferencedColumnName: "id"}}, user.ReferenceKeys[0].Columns)
+	assert.Equal(t, "project_user_id_fkey", user.ReferenceKeys[1].Name)
+
+	project := tables.Get("project")
+	require.Len(t, project.ForeignKeys, 1)
+	assert.Equal(t, "project_user_id_fkey", project.ForeignKeys[0].Name)
+	assert.Equal(t, []string{"user_id"}, project.ForeignKeys[0].Columns.ColumnNames())
+	assert.Equal(t, "user", project.ForeignKeys[0].ReferencedTable.Name)
+	assert.Equal(t, []string{"id"}, project.ForeignKeys[0].Columns.ReferencedColumnNames())
+
+	backer := tables.Get("backer")
+	require.Len(t, backer.PrimaryKeys, 2)
+	assert.Equal(t, "user_id", backer.PrimaryKeys[0].Name)
+	assert.Equal(t, "project_id", backer.PrimaryKeys[1].Name)
+	require.Len(t, backer.ForeignKeys, 2)
+	assert.Equal(t, []string{"id"}, backer.ForeignKeys[1].Columns.ReferencedColumnNames())
+	require.Len(t, backer.ReferenceKeys, 1)
+	assert.Equal(t, "contribution_user_id_project_id_fkey", backer.ReferenceKeys[0].Name)
+
+	contribution := tables.Get("contribution")
+	require.Len(t, contribution.ForeignKeys, 1)
+	assert.Equal(t, dialect.ColumnReferences{
+		{ColumnName: "user_id", ReferencedColumnName: "user_id"},
+		{ColumnName: "project_id", ReferencedColumnName: "project_id"},
+	}, contribution.ForeignKeys[0].Columns)
+
+	membership := tables.Get("membership")
+	assert.Empty(t, membership.PrimaryKeys)
+	assert.Equal(t, []dialect.UniqueKey{{Name: "membership_email_key", ColumnNames: []string{"email"}}}, membership.UniqueKeys)
+	assert.Equal(t, []string{"email"}, membership.KeyColumnNames())
+	assert.Nil(t, tables.Get("log").KeyColumnNames())
+
+	assert.Equal(t, dialect.TableKindTable, user.Kind)
+
+	view, err := d.Table(ctx, "project_owner")
+	require.NoError(t, err)
+	assert.Equal(t, dialect.TableKindView, view.Kind)
+	assert.Equal(t, []string{"project_id", "username"}, columnNames(view.Columns))
+	assert.Error(t, d.BulkInsert(ctx, view, []map[string]interface{}{{"project_id": 1}}, dialect.InsertOptions{}))
+
+	constraint, err := d.PrimaryKeyConstraint(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "user_pkey", constraint)
+}
+
+func TestBulkInsert(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	_, err := d.db.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	require.NoError(t, err)
+
+	project, err := d.Table(ctx, "project")
+	require.NoError(t, err)
+
+	// The referenced user does not exist yet, foreign keys must not be enforced.
+	err = d.BulkInsert(ctx, project, []map[string]interface{}{
+		{"id": float64(1), "name": "mover", "user_id": float64(1)},
+		{"id": float64(2), "name": "loukoum", "user_id": float64(1)},
+	}, dialect.InsertOptions{})
+	require.NoError(t, err)
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+
+	rows := []map[string]interface{}{
+		{"id": float64(1), "username": "thoas", "profile": map[string]interface{}{"lang": "fr"}},
+	}
+	require.NoError(t, d.BulkInsert(ctx, user, rows, dialect.InsertOptions{}))
+
+	// Conflicting rows are skipped.
+	rows[0]["username"] = "ulule"
+	require.NoError(t, d.BulkInsert(ctx, user, rows, dialect.InsertOptions{}))
+
+	results, err := d.ResultSet(ctx, `SELECT * FROM "user" WHERE ("id" = $1)`, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "thoas", results[0]["username"])
+	assert.Equal(t, `{"lang":"fr"}`, results[0]["profile"])
+
+	results, err = d.ResultSet(ctx, `SELECT * FROM "project" WHERE ("user_id" = $2 AND "name" <> $1) ORDER BY "id"`, "mover", 1)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, int64(2), results[0]["id"])
+
+	var enabled bool
+	require.NoError(t, d.db.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled))
+	assert.True(t, enabled)
+}
+
+func TestBulkInsertCompositeKey(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	backer, err := d.Table(ctx, "backer")
+	require.NoError(t, err)
+
+	rows := []map[string]interface{}{
+		{"user_id": 1, "project_id": 1},
+		{"user_id": 1, "project_id": 2},
+		{"user_id": 2, "project_id": 1},
+	}
+	require.NoError(t, d.BulkInsert(ctx, backer, rows, dialect.InsertOptions{}))
+
+	// Rows are only conflicting on the full key.
+	require.NoError(t, d.BulkInsert(ctx, backer, append(rows, map[string]interface{}{"user_id": 2, "project_id": 2}), dialect.InsertOptions{}))
+
+	results, err := d.ResultSet(ctx, `SELECT * FROM "backer"`)
+	require.NoError(t, err)
+	assert.Len(t, results, 4)
+}
+
+func TestBulkInsertConflictStrategies(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	count := func(tableName string) int {
+		var count int
+		require.NoError(t, d.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoteIdentifier(tableName)).Scan(&count))
+		return count
+	}
+
+	log, err := d.Table(ctx, "log")
+	require.NoError(t, err)
+
+	rows := []map[string]interface{}{
+		{"user_id": 1, "action": "login"},
+		{"user_id": nil, "action": "cleanup"},
+	}
+	require.NoError(t, d.BulkInsert(ctx, log, rows, dialect.InsertOptions{}))
+	require.NoError(t, d.BulkInsert(ctx, log, rows, dialect.InsertOptions{Conflict: dialect.ConflictSkipDuplicates}))
+	assert.Equal(t, 2, count("log"))
+
+	require.NoError(t, d.BulkInsert(ctx, log, rows, dialect.InsertOptions{Conflict: dialect.ConflictAppend}))
+	assert.Equal(t, 4, count("log"))
+
+	membership, err := d.Table(ctx, "membership")
+	require.NoError(t, err)
+
+	// Without a primary key, rows conflicting on a unique index are skipped.
+	require.NoError(t, d.BulkInsert(ctx, membership, []map[string]interface{}{
+		{"email": "florent@ulule.com", "role": "admin"},
+		{"email": "florent@ulule.com", "role": "owner"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictNothing}))
+	assert.Equal(t, 1, count("membership"))
+
+	assert.Error(t, d.BulkInsert(ctx, membership, []map[string]interface{}{
+		{"email": "florent@ulule.com", "role": "owner"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictAppend}))
+}
+
+func TestBulkInsertUpdateReplaceFail(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	user, err := d.Table(ctx, "user")
+	require.NoError(t, err)
+
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": 1, "username": "thoas", "profile": "admin"},
+		{"id": 2, "username": "ulule", "profile": "staff"},
+	}, dialect.InsertOptions{}))
+
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": 1, "username": "florent", "profile": "owner"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictUpdate, UpdateColumns: []string{"username"}}))
+
+	require.NoError(t, d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": 2, "username": "replaced"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictReplace}))
+
+	results, err := d.ResultSet(ctx, `SELECT * FROM "user" ORDER BY "id"`)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "florent", results[0]["username"])
+	assert.Equal(t, "admin", results[0]["profile"])
+	assert.Equal(t, "replaced", results[1]["username"])
+	assert.Nil(t, results[1]["profile"])
+
+	err = d.BulkInsert(ctx, user, []map[string]interface{}{
+		{"id": 3, "username": "new"},
+		{"id": 2, "username": "conflict"},
+	}, dialect.InsertOptions{Conflict: dialect.ConflictFail})
+
+	var conflictErr *dialect.ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, [][]interface{}{{2}}, conflictErr.Keys)
+
+	results, err = d.ResultSet(ctx, `SELECT * FROM "user"`)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
+
+func TestDanglingReferences(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	_, err := d.db.ExecContext(ctx, `
+INSERT INTO user (id, username) VALUES (1, 'thoas');
+INSERT INTO project (id, name, user_id) VALUES (1, 'mover', 1), (2, 'orphan', 2);
+INSERT INTO backer (user_id, project_id) VALUES (1, 1);
+INSERT INTO contribution (id, user_id, project_id) VALUES (1, 1, 1), (2, 1, 2), (3, NULL, 2);
+`)
+	require.NoError(t, err)
+
+	project, err := d.Table(ctx, "project")
+	require.NoError(t, err)
+
+	count, rows, err := d.DanglingReferences(ctx, project, project.ForeignKeys[0], 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "orphan", rows[0]["name"])
+
+	// Foreign keys with a NULL column reference no row.
+	contribution, err := d.Table(ctx, "contribution")
+	require.NoError(t, err)
+
+	count, rows, err = d.DanglingReferences(ctx, contribution, contribution.ForeignKeys[0], 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.Empty(t, rows)
+}
+
+func TestRewritePlaceholders(t *testing.T) {
+	assert.Equal(t, `SELECT * FROM "t" WHERE ("a" = ?1) AND b = '$2'`, rewritePlaceholders(`SELECT * FROM "t" WHERE ("a" = $1) AND b = '$2'`))
+}
+
+func TestTablesSchemas(t *testing.T) {
+	var (
+		ctx = context.Background()
+		d   = newTestDialect(t)
+	)
+
+	_, err := d.db.ExecContext(ctx, `ATTACH DATABASE ? AS billing`, filepath.Join(t.TempDir(), "billing.db"))
+	require.NoError(t, err)
+	_, err = d.db.ExecContext(ctx, `
+CREATE TABLE billing.invoice (id INTEGER PRIMARY KEY, amount INTEGER);
+CREATE TABLE billing.line (id INTEGER PRIMARY KEY, invoice_id INTEGER REFERENCES invoice(id));
+`)
+	require.NoError(t, err)
+
+	tables, err := d.Tables(ctx, "main", "billing")
+	require.NoError(t, err)
+	require.Len(t, tables, 9)
+
+	invoice := tables.Get("billing.invoice")
+	assert.Equal(t, "billing", invoice.Schema)
+	assert.Equal(t, "billing.invoice", invoice.Columns[0].TableName)
+	require.Len(t, invoice.ReferenceKeys, 1)
+	assert.Equal(t, "billing.line", invoice.ReferenceKeys[0].Table.Name)
+
+	line := tables.Get("billing.line")
+	require.Len(t, line.ForeignKeys, 1)
+	assert.Equal(t, "billing.invoice", line.ForeignKeys[0].ReferencedTable.Name)
+
+	require.NoError(t, d.BulkInsert(ctx, invoice, []map[string]interface{}{{"id": 1, "amount": 100}}, dialect.InsertOptions{}))
+	results, err := d.ResultSet(ctx, `SELECT * FROM "billing"."invoice"`)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func columnNames(columns dialect.Columns) []string {
+	names := make([]string, len(columns))
+	for i := range columns {
+		names[i] = columns[i].Name
+	}
+
+	return names
+}
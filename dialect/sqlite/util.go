@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ulule/mover/dialect"
+)
+
+// parseDSN accepts sqlite://path/to/file.db, sqlite:///absolute/path.db and native driver DSNs.
+func parseDSN(dsn string) string {
+	for _, prefix := range []string{"sqlite3://", "sqlite://"} {
+		if strings.HasPrefix(dsn, prefix) {
+			return strings.TrimPrefix(dsn, prefix)
+		}
+	}
+
+	return dsn
+}
+
+// rewritePlaceholders converts PostgreSQL positional placeholders ($1, $2, ...) to SQLite
+// numbered ones (?1, ?2, ...) which are bound by their index instead of their order of appearance.
+func rewritePlaceholders(query string) string {
+	var (
+		b     strings.Builder
+		quote byte
+	)
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '$' && i+1 < len(query) && query[i+1] >= '0' && query[i+1] <= '9':
+			c = '?'
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// splitName returns the schema and the name of a table.
+func splitName(tableName string) (string, string) {
+	schema, name := dialect.SplitName(tableName)
+	if schema == "" {
+		schema = defaultSchema
+	}
+
+	return schema, name
+}
+
+// qualifiedName returns the name of a table qualified by its schema unless it belongs to the main database.
+func qualifiedName(schema, name string) string {
+	if schema == defaultSchema {
+		schema = ""
+	}
+
+	return dialect.QualifiedName(schema, name)
+}
+
+func quoteIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i := range parts {
+		parts[i] = `"` + strings.ReplaceAll(parts[i], `"`, `""`) + `"`
+	}
+
+	return strings.Join(parts, ".")
+}
+
+func quoteIdentifiers(names []string) []string {
+	results := make([]string, len(names))
+	for i := range names {
+		results[i] = quoteIdentifier(names[i])
+	}
+
+	return results
+}
+
+func foreignKeyName(tableName string, columnNames []string) string {
+	return fmt.Sprintf("%s_%s_fkey", tableName, strings.Join(columnNames, "_"))
+}
+
+func columnReferences(columnNames, referencedColumnNames []string) dialect.ColumnReferences {
+	references := make(dialect.ColumnReferences, len(columnNames))
+	for i := range columnNames {
+		references[i] = dialect.ColumnReference{
+			ColumnName:           columnNames[i],
+			ReferencedColumnName: referencedColumnNames[i],
+		}
+	}
+
+	return references
+}
+
+// keyValues returns the values of the key columns among sorted columns, false when the table has
+// no key or when a key value is NULL since such rows never conflict.
+func keyValues(keyColumnNames, columns []string, args []interface{}) ([]interface{}, bool) {
+	if len(keyColumnNames) == 0 {
+		return nil, false
+	}
+
+	values := make([]interface{}, len(keyColumnNames))
+	for i := range keyColumnNames {
+		j := sort.SearchStrings(columns, keyColumnNames[i])
+		if j == len(columns) || columns[j] != keyColumnNames[i] || args[j] == nil {
+			return nil, false
+		}
+
+		values[i] = args[j]
+	}
+
+	return values, true
+}
+
+func valuesToArgs(data map[string]interface{}) ([]string, []interface{}, error) {
+	columns := make([]string, 0, len(data))
+	for k := range data {
+		columns = append(columns, k)
+	}
+	sort.Strings(columns)
+
+	args := make([]interface{}, len(columns))
+	for i, k := range columns {
+		switch v := data[k].(type) {
+		case map[string]interface{}, []interface{}:
+			res, err := json.Marshal(v)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to encode %v to JSON: %w", v, err)
+			}
+
+			args[i] = string(res)
+		default:
+			args[i] = v
+		}
+	}
+
+	return columns, args, nil
+}
+
+func marshalRows(rows *sql.Rows) (map[string]interface{}, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(columns))
+	pointers := make([]interface{}, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]interface{}, len(columns))
+	for i := range columns {
+		results[columns[i]] = values[i]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,129 @@
+package etl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+	dialectpkg "github.com/ulule/mover/dialect"
+)
+
+// schemaCacheEntry is the content of a schema cache file.
+type schemaCacheEntry struct {
+	Identity    string            `json:"identity"`
+	Schemas     []string          `json:"schemas"`
+	Fingerprint string            `json:"fingerprint"`
+	Tables      dialectpkg.Tables `json:"tables"`
+}
+
+// loadTables returns the tables of the configured schemas. They are read from the schema cache when
+// the dialect computes schema fingerprints and the schema has not changed since they were cached.
+func loadTables(ctx context.Context, cfg config.Config, dialect dialectpkg.Dialect, logger *zap.Logger) (dialectpkg.Tables, error) {
+	fingerprinter, ok := dialect.(dialectpkg.SchemaFingerprinter)
+	if cfg.SchemaCache.Path == "" || !ok {
+		return dialect.Tables(ctx, cfg.Schemas...)
+	}
+
+	fingerprint, err := fingerprinter.SchemaFingerprint(ctx, cfg.Schemas...)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath := schemaCachePath(cfg.SchemaCache.Path, fingerprinter.Identity(), cfg.Schemas)
+	if !cfg.SchemaCache.Refresh {
+		entry, err := readSchemaCache(filePath)
+		if err != nil {
+			logger.Warn("unable to read schema cache", zap.Error(err), zap.String("path", filePath))
+		}
+
+		if entry != nil && entry.Fingerprint == fingerprint {
+			logger.Debug("Use schema cache", zap.String("path", filePath))
+			entry.Tables.Link()
+
+			return entry.Tables, nil
+		}
+	}
+
+	tables, err := dialect.Tables(ctx, cfg.Schemas...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSchemaCache(filePath, schemaCacheEntry{
+		Identity:    fingerprinter.Identity(),
+		Schemas:     cfg.Schemas,
+		Fingerprint: fingerprint,
+		Tables:      tables,
+	}); err != nil {
+		logger.Warn("unable to write schema cache", zap.Error(err), zap.String("path", filePath))
+	}
+
+	return tables, nil
+}
+
+// schemaCachePath returns the cache file of a database and a set of schemas.
+func schemaCachePath(dirPath, identity string, schemas []string) string {
+	hash := sha256.Sum256([]byte(identity + "\n" + strings.Join(schemas, ",")))
+
+	return filepath.Join(dirPath, "schema-"+hex.EncodeToString(hash[:8])+".json")
+}
+
+// readSchemaCache returns the cached entry of a file, a missing file is not an error.
+func readSchemaCache(filePath string) (*schemaCacheEntry, error) {
+	content, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", filePath, err)
+	}
+
+	var entry schemaCacheEntry
+	if err := json.Unmarshal(content, &entry); err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %w", filePath, err)
+	}
+
+	return &entry, nil
+}
+
+// writeSchemaCache replaces the cache file atomically so concurrent runs never read a partial file.
+func writeSchemaCache(filePath string, entry schemaCacheEntry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to encode schema cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("unable to create directory %s: %w", filepath.Dir(filePath), err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file for %s: %w", filePath, err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write %s: %w", f.Name(), err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write %s: %w", f.Name(), err)
+	}
+
+	if err := os.Rename(f.Name(), filePath); err != nil {
+		return fmt.Errorf("unable to rename %s to %s: %w", f.Name(), filePath, err)
+	}
+
+	return nil
+}
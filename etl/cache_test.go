@@ -0,0 +1,68 @@
+package etl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect/memory"
+)
+
+func TestSchemaCache(t *testing.T) {
+	var (
+		ctx = context.Background()
+		cfg = config.Config{SchemaCache: config.SchemaCache{Path: t.TempDir()}}
+	)
+
+	fixture, err := memory.LoadFixture(filepath.Join("testdata", "backers.json"))
+	require.NoError(t, err)
+	d, err := fixture.Dialect()
+	require.NoError(t, err)
+
+	engine, err := NewEngineWithDialect(ctx, cfg, d, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.Introspections())
+
+	// The schema has not changed, tables are read from the cache and linked again.
+	cached, err := NewEngineWithDialect(ctx, cfg, d, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.Introspections())
+
+	for tableName, schema := range engine.schema {
+		assert.Equal(t, schema.Table, cached.schema[tableName].Table, tableName)
+	}
+	assert.Equal(t, "backer", cached.schema["contribution"].Table.ForeignKeys[0].ReferencedTable.Name)
+
+	cfg.SchemaCache.Refresh = true
+	_, err = NewEngineWithDialect(ctx, cfg, d, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 2, d.Introspections())
+
+	// A cache written for another fingerprint is ignored.
+	cfg.SchemaCache.Refresh = false
+	filePath := schemaCachePath(cfg.SchemaCache.Path, d.Identity(), cfg.Schemas)
+	entry, err := readSchemaCache(filePath)
+	require.NoError(t, err)
+	entry.Fingerprint = "outdated"
+	require.NoError(t, writeSchemaCache(filePath, *entry))
+
+	_, err = NewEngineWithDialect(ctx, cfg, d, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 3, d.Introspections())
+
+	// A corrupted cache is replaced.
+	require.NoError(t, os.WriteFile(filePath, []byte("{"), 0644))
+	_, err = NewEngineWithDialect(ctx, cfg, d, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, 4, d.Introspections())
+
+	entry, err = readSchemaCache(filePath)
+	require.NoError(t, err)
+	assert.NotEqual(t, "outdated", entry.Fingerprint)
+}
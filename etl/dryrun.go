@@ -0,0 +1,195 @@
+package etl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect"
+)
+
+// columnDiff contains the existing and the loaded values of a column.
+type columnDiff struct {
+	ColumnName string
+	Existing   interface{}
+	Loaded     interface{}
+}
+
+func (d columnDiff) String() string {
+	return fmt.Sprintf("%s: %v -> %v", d.ColumnName, d.Existing, d.Loaded)
+}
+
+// rowDiff contains the columns of a loaded row which differ from the existing row with the same key.
+type rowDiff struct {
+	Key     []interface{}
+	Columns []columnDiff
+}
+
+// tableReport reports what a load would change in a table.
+type tableReport struct {
+	TableName string
+	Conflict  dialect.ConflictStrategy
+	// Inserted counts the rows which do not exist, Skipped the rows identical to existing rows.
+	Inserted int
+	Skipped  int
+	// Different counts the rows with the same key as existing rows but different values, Differences
+	// contains the first maxReportedRows of them.
+	Different   int
+	Differences []rowDiff
+}
+
+// report compares the rows of files with the existing rows without writing anything,
+// rows are matched on the key of their table.
+func (l *loader) report(ctx context.Context, files []string) ([]tableReport, error) {
+	reports := make([]tableReport, 0, len(files))
+	for _, file := range files {
+		schema := l.schema[fileTableName(file)]
+		if !schema.Table.Insertable() {
+			continue
+		}
+
+		report := l.newTableReport(schema)
+		if err := streamFile(file, l.batchSize, func(tableName string, rows []map[string]interface{}) error {
+			if err := l.compareRows(ctx, schema, rows, &report); err != nil {
+				return fmt.Errorf("unable to compare rows of %s: %w", tableName, err)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("unable to load file %s: %w", file, err)
+		}
+
+		l.logger.Info("Dry run",
+			zap.String("table", report.TableName),
+			zap.String("conflict", string(report.Conflict)),
+			zap.Int("inserted", report.Inserted),
+			zap.Int("skipped", report.Skipped),
+			zap.Int("different", report.Different))
+
+		for _, diff := range report.Differences {
+			columns := make([]string, len(diff.Columns))
+			for j := range diff.Columns {
+				columns[j] = diff.Columns[j].String()
+			}
+
+			l.logger.Info("Row differs from existing row",
+				zap.String("table", report.TableName),
+				zap.String("key", joinValues(diff.Key)),
+				zap.Strings("columns", columns))
+		}
+
+		if report.Different > len(report.Differences) {
+			l.logger.Info("More rows differ from existing rows",
+				zap.String("table", report.TableName),
+				zap.Int("count", report.Different-len(report.Differences)))
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (l *loader) newTableReport(schema config.Schema) tableReport {
+	return tableReport{
+		TableName: schema.Table.Name,
+		Conflict:  l.conflictStrategy(schema),
+	}
+}
+
+// compareRows adds a batch of rows to the report of their table, existing rows are fetched by chunks.
+func (l *loader) compareRows(ctx context.Context, schema config.Schema, data []map[string]interface{}, report *tableReport) error {
+	// Remapped rows are inserted with new keys.
+	keyColumnNames := schema.Table.KeyColumnNames()
+	if _, ok := remappedColumn(schema.Table); ok && l.remap {
+		keyColumnNames = nil
+	}
+
+	keys := make([][]interface{}, 0, len(data))
+	for _, row := range data {
+		if values := rowValues(row, keyColumnNames); len(keyColumnNames) > 0 && !hasNil(values) {
+			keys = append(keys, values)
+		}
+	}
+
+	var existing map[string]map[string]interface{}
+	if len(keys) > 0 {
+		var err error
+		if existing, err = existingRows(ctx, l.dialect, schema.Table.Name, keyColumnNames, keys); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range data {
+		values := rowValues(row, keyColumnNames)
+		if len(keyColumnNames) == 0 || hasNil(values) {
+			report.Inserted++
+			continue
+		}
+
+		existingRow, ok := existing[existingKey(values)]
+		if !ok {
+			report.Inserted++
+			continue
+		}
+
+		columns := diffColumns(existingRow, row)
+		if len(columns) == 0 {
+			report.Skipped++
+			continue
+		}
+
+		report.Different++
+		if len(report.Differences) < maxReportedRows {
+			report.Differences = append(report.Differences, rowDiff{Key: values, Columns: columns})
+		}
+	}
+
+	return nil
+}
+
+// diffColumns returns the loaded columns whose value differs from the existing one, values are
+// compared in JSON since loaded rows are decoded from JSON.
+func diffColumns(existing, loaded map[string]interface{}) []columnDiff {
+	columnNames := make([]string, 0, len(loaded))
+	for columnName := range loaded {
+		columnNames = append(columnNames, columnName)
+	}
+	sort.Strings(columnNames)
+
+	diffs := make([]columnDiff, 0)
+	for _, columnName := range columnNames {
+		existingValue, loadedValue := normalizeValue(existing[columnName]), normalizeValue(loaded[columnName])
+		if reflect.DeepEqual(existingValue, loadedValue) {
+			continue
+		}
+
+		diffs = append(diffs, columnDiff{
+			ColumnName: columnName,
+			Existing:   existingValue,
+			Loaded:     loadedValue,
+		})
+	}
+
+	return diffs
+}
+
+// normalizeValue returns a value as decoded from its JSON representation.
+func normalizeValue(value interface{}) interface{} {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return value
+	}
+
+	return normalized
+}
@@ -0,0 +1,74 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/config"
+)
+
+func TestLoadDryRun(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 1, "username": "thoas", "email": "florent@ulule.com"},
+			{"id": 2, "username": "ulule", "email": "hello@ulule.com"},
+			{"id": 4, "username": "loader", "email": "loader@ulule.com"},
+		},
+	})
+
+	engine, d := newTestEngine(t, "fixture.json", config.Config{DryRun: true})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Empty(t, d.Inserts())
+	assert.Empty(t, d.Loads())
+	assert.Len(t, d.Rows("user"), 3)
+
+	files, err := listFiles(outputPath)
+	require.NoError(t, err)
+
+	// Existing rows of a batch are fetched by a single query.
+	queries := len(d.Queries())
+	reports, err := engine.newLoader().report(ctx, files)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Len(t, d.Queries(), queries+1)
+
+	report := reports[0]
+	assert.Equal(t, "user", report.TableName)
+	assert.Equal(t, 1, report.Inserted)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, 1, report.Different)
+	require.Len(t, report.Differences, 1)
+	assert.Equal(t, []interface{}{float64(2)}, report.Differences[0].Key)
+	assert.Equal(t, []columnDiff{
+		{ColumnName: "email", Existing: "contact@ulule.com", Loaded: "hello@ulule.com"},
+	}, report.Differences[0].Columns)
+
+	// Remapped rows are always inserted.
+	engine, _ = newTestEngine(t, "fixture.json", config.Config{DryRun: true, Remap: true})
+	reports, err = engine.newLoader().report(ctx, files)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reports[0].Inserted)
+
+	// Only the first different rows are kept.
+	data := make([]map[string]interface{}, maxReportedRows+2)
+	for i := range data {
+		data[i] = map[string]interface{}{"id": 2, "username": "ulule", "email": fmt.Sprintf("%d@ulule.com", i)}
+	}
+	writePayload(t, outputPath, jsonPayload{TableName: "user", Data: data})
+
+	engine, _ = newTestEngine(t, "fixture.json", config.Config{DryRun: true})
+	reports, err = engine.newLoader().report(ctx, files)
+	require.NoError(t, err)
+	assert.Equal(t, maxReportedRows+2, reports[0].Different)
+	assert.Len(t, reports[0].Differences, maxReportedRows)
+}
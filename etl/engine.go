@@ -2,9 +2,7 @@ package etl
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
 	"path"
 	"strings"
 
@@ -13,7 +11,6 @@ import (
 	lk "github.com/ulule/loukoum/v3"
 	"github.com/ulule/mover/config"
 	dialectpkg "github.com/ulule/mover/dialect"
-	"github.com/ulule/mover/dialect/postgres"
 )
 
 // copySchemaTables copies tables from database to schema configuration.
@@ -49,6 +46,86 @@ func copySchemaTables(schema []config.Schema, tables []dialectpkg.Table) map[str
 	return schemas
 }
 
+// declareVirtualKeys adds the primary and foreign keys declared in the configuration to the tables,
+// views have no constraint and need them to be traversed.
+func declareVirtualKeys(schema []config.Schema, tables dialectpkg.Tables) error {
+	for i := range schema {
+		if len(schema[i].PrimaryKeys) == 0 && len(schema[i].ForeignKeys) == 0 {
+			continue
+		}
+
+		table := findTable(tables, schema[i].TableName)
+		if table == nil {
+			return fmt.Errorf("unable to declare keys of table %s: table does not exist", schema[i].TableName)
+		}
+
+		if len(table.PrimaryKeys) == 0 {
+			for _, name := range schema[i].PrimaryKeys {
+				column := table.Columns.Get(name)
+				if column.Name == "" {
+					return fmt.Errorf("unable to declare primary key of table %s: column %s does not exist", table.Name, name)
+				}
+
+				table.PrimaryKeys = append(table.PrimaryKeys, dialectpkg.PrimaryKey{
+					Name:      name,
+					DataType:  column.DataType,
+					TableName: table.Name,
+				})
+			}
+		}
+
+		for _, foreignKey := range schema[i].ForeignKeys {
+			if len(foreignKey.ColumnNames) == 0 || len(foreignKey.ColumnNames) != len(foreignKey.ReferencedColumnNames) {
+				return fmt.Errorf("unable to declare foreign key %s of table %s: %d columns for %d referenced columns",
+					foreignKey.Name, table.Name, len(foreignKey.ColumnNames), len(foreignKey.ReferencedColumnNames))
+			}
+
+			if findTable(tables, foreignKey.ReferencedTableName) == nil {
+				return fmt.Errorf("unable to declare foreign key %s of table %s: table %s does not exist",
+					foreignKey.Name, table.Name, foreignKey.ReferencedTableName)
+			}
+
+			name := foreignKey.Name
+			if name == "" {
+				_, tableName := dialectpkg.SplitName(table.Name)
+				name = fmt.Sprintf("%s_%s_fkey", tableName, strings.Join(foreignKey.ColumnNames, "_"))
+			}
+
+			columns := make(dialectpkg.ColumnReferences, len(foreignKey.ColumnNames))
+			for j := range foreignKey.ColumnNames {
+				columns[j] = dialectpkg.ColumnReference{
+					ColumnName:           foreignKey.ColumnNames[j],
+					ReferencedColumnName: foreignKey.ReferencedColumnNames[j],
+				}
+			}
+
+			table.ForeignKeys = append(table.ForeignKeys, dialectpkg.ForeignKey{
+				Name:                name,
+				Columns:             columns,
+				ReferencedTableName: foreignKey.ReferencedTableName,
+			})
+		}
+	}
+
+	for i := range tables {
+		for j := range tables[i].ForeignKeys {
+			tables[i].ForeignKeys[j].ReferencedTable = tables.Get(tables[i].ForeignKeys[j].ReferencedTableName)
+		}
+	}
+
+	return nil
+}
+
+func findTable(tables dialectpkg.Tables, tableName string) *dialectpkg.Table {
+	for i := range tables {
+		if tables[i].Name == tableName {
+			return &tables[i]
+		}
+	}
+
+	return nil
+}
+
 // Engine extracts and loads data from database with specific dialect.
 type Engine struct {
 	schema  map[string]config.Schema
@@ -63,18 +140,64 @@ type jsonPayload struct {
 	Data      []map[string]interface{} `json:"data"`
 }
 
-// NewEngine returns a new Engine instance.
+// NewEngine returns a new Engine instance with the dialect resolved from the DSN scheme.
+//
+// Dialects must be registered beforehand by importing their package, e.g.
+// import _ "github.com/ulule/mover/dialect/postgres".
 func NewEngine(ctx context.Context, cfg config.Config, dsn string, logger *zap.Logger) (*Engine, error) {
-	dialect, err := postgres.NewPGDialect(ctx, dsn)
+	dialect, err := dialectpkg.Open(ctx, "", dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	tables, err := dialect.Tables(ctx)
+	return NewEngineWithDialect(ctx, cfg, dialect, logger)
+}
+
+// NewEngineWithDialect returns a new Engine instance using an initialized dialect.
+func NewEngineWithDialect(ctx context.Context, cfg config.Config, dialect dialectpkg.Dialect, logger *zap.Logger) (*Engine, error) {
+	if err := cfg.Conflict.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := cfg.LoadMode.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if err := cfg.Verify.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if cfg.BatchSize < 0 {
+		return nil, fmt.Errorf("invalid configuration: negative batch size %d", cfg.BatchSize)
+	}
+
+	if cfg.Workers < 0 {
+		return nil, fmt.Errorf("invalid configuration: negative number of workers %d", cfg.Workers)
+	}
+
+	if _, ok := dialect.(dialectpkg.LoadSession); cfg.Atomic && !ok {
+		return nil, fmt.Errorf("invalid configuration: atomic loads are not supported by the dialect")
+	}
+
+	if _, ok := dialect.(dialectpkg.KeyAllocator); cfg.Remap && !ok {
+		return nil, fmt.Errorf("invalid configuration: key remapping is not supported by the dialect")
+	}
+
+	for i := range cfg.Schema {
+		if err := cfg.Schema[i].Conflict.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration for table %s: %w", cfg.Schema[i].TableName, err)
+		}
+	}
+
+	tables, err := loadTables(ctx, cfg, dialect, logger)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := declareVirtualKeys(cfg.Schema, tables); err != nil {
+		return nil, err
+	}
+
 	schema := copySchemaTables(cfg.Schema, tables)
 
 	return &Engine{
@@ -100,17 +223,55 @@ func (e *Engine) Load(ctx context.Context, outputPath string) error {
 	return e.newLoader().Load(ctx, outputPath)
 }
 
+// Verify reports the rows of the tables of an output directory referencing rows which do not exist,
+// every table is verified when the output directory is empty.
+func (e *Engine) Verify(ctx context.Context, outputPath string) error {
+	return e.newLoader().Verify(ctx, outputPath)
+}
+
+// Unload deletes the rows inserted by the loads of an output directory.
+func (e *Engine) Unload(ctx context.Context, outputPath string) error {
+	return e.newLoader().Unload(ctx, outputPath)
+}
+
 // Extract extracts data to an output directory with a table name and its query.
+// Rows are spilled to temporary files in the output directory until they are written.
 func (e *Engine) Extract(ctx context.Context, outputPath, query string) error {
-	extractor := e.newExtractor()
-
 	tableName := getQueryTable(query)
 	if tableName == "" {
 		return fmt.Errorf("unable to retrieve table from query: %s", query)
 	}
 
-	cache, err := extractor.Handle(ctx, e.schema[tableName], query)
+	extractor, err := e.newExtractor(outputPath)
 	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := extractor.spill.Close(); err != nil {
+			e.logger.Error("unable to remove spill files", zap.Error(err))
+		}
+	}()
+
+	if extractor.workers > 1 {
+		snapshotter, ok := e.dialect.(dialectpkg.Snapshotter)
+		if !ok {
+			e.logger.Info("Extract sequentially, the dialect cannot share a snapshot between workers")
+			extractor.workers = 1
+		} else {
+			if extractor.workers, err = snapshotter.BeginSnapshot(ctx, extractor.workers); err != nil {
+				return err
+			}
+			defer func() {
+				if err := snapshotter.EndSnapshot(ctx); err != nil {
+					e.logger.Error("unable to end snapshot", zap.Error(err))
+				}
+			}()
+
+			e.logger.Info("Extract with workers", zap.Int("workers", extractor.workers))
+		}
+	}
+
+	if err := extractor.Handle(ctx, e.schema[tableName], query); err != nil {
 		return fmt.Errorf("unable to extract %s (query %s): %w", tableName, query, err)
 	}
 
@@ -118,14 +279,13 @@ func (e *Engine) Extract(ctx context.Context, outputPath, query string) error {
 		tableName := e.config.Extra[i].TableName
 		query, _ := lk.Select(lk.Raw("*")).
 			From(tableName).Query()
-		cache, err = extractor.Handle(ctx, e.schema[tableName], query)
-		if err != nil {
+		if err := extractor.Handle(ctx, e.schema[tableName], query); err != nil {
 			return fmt.Errorf("unable to extract %s (query %s): %w", tableName, query, err)
 		}
 	}
 
-	for tableName := range cache {
-		if err := e.extract(ctx, outputPath, e.schema[tableName], cache[tableName]); err != nil {
+	for _, tableName := range extractor.spill.tableNames() {
+		if err := e.extract(ctx, outputPath, e.schema[tableName], extractor.spill); err != nil {
 			return fmt.Errorf("unable to extract rows from table %s: %w", tableName, err)
 		}
 	}
@@ -138,32 +298,31 @@ func (e *Engine) Shutdown(ctx context.Context) error {
 	return e.dialect.Close(ctx)
 }
 
-func (e *Engine) extract(ctx context.Context, outputPath string, schema config.Schema, rows entry) error {
+// extract writes the spilled rows of a table to its file in the output directory, row by row.
+func (e *Engine) extract(ctx context.Context, outputPath string, schema config.Schema, spill *spill) error {
 	var (
-		table   = schema.Table
-		results = e.newSanitizer().sanitize(table, rows)
-		payload = jsonPayload{
-			TableName: table.Name,
-			Count:     len(results),
-			Data:      results,
-		}
+		table     = schema.Table
+		sanitizer = e.newSanitizer()
+		count     = spill.count(table.Name)
+		filenames = make([]string, 0)
 	)
 
-	output, err := json.MarshalIndent(payload, "", "\t")
-	if err != nil {
-		return fmt.Errorf("unable to encode in JSON: %w", err)
-	}
-
 	filePath := path.Join(outputPath, table.Name+extensionFormat)
-	if err := os.WriteFile(filePath, output, 0644); err != nil {
-		return fmt.Errorf("unable to write JSON output to %s: %w", filePath, err)
+	if err := writeFile(filePath, table.Name, count, func(write func(row map[string]interface{}) error) error {
+		return spill.read(table.Name, func(row map[string]interface{}) error {
+			row = sanitizer.sanitize(table, row)
+			filenames = append(filenames, extractFilenames(schema, row)...)
+
+			return write(row)
+		})
+	}); err != nil {
+		return err
 	}
 
-	e.logger.Info(fmt.Sprintf("Export %d results", len(results)),
+	e.logger.Info(fmt.Sprintf("Export %d results", count),
 		zap.String("table", table.Name),
 		zap.String("path", filePath))
 
-	filenames := extractFilenames(schema, rows)
 	if len(filenames) > 0 {
 		e.logger.Debug("Download files",
 			zap.String("files", strings.Join(filenames, " ")),
@@ -179,20 +338,41 @@ func (e *Engine) extract(ctx context.Context, outputPath string, schema config.S
 
 func (e *Engine) newLoader() *loader {
 	return &loader{
-		dialect: e.dialect,
-		schema:  e.schema,
-		logger:  e.logger,
+		dialect:     e.dialect,
+		schema:      e.schema,
+		logger:      e.logger,
+		conflict:    e.config.Conflict,
+		mode:        e.config.LoadMode,
+		atomic:      e.config.Atomic,
+		remap:       e.config.Remap,
+		journalPath: e.config.Journal,
+		verify:      e.config.Verify,
+		dryRun:      e.config.DryRun,
+		batchSize:   e.config.BatchSize,
 	}
 }
 
-func (e *Engine) newExtractor() *extractor {
+func (e *Engine) newExtractor(outputPath string) (*extractor, error) {
+	spill, err := newSpill(outputPath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := e.config.Workers
+	if workers == 0 {
+		workers = defaultWorkers
+	}
+
 	return &extractor{
-		extract:            make(extract),
+		spill:              spill,
+		queries:            make(map[string]struct{}),
+		requested:          make(map[string]struct{}),
+		workers:            workers,
 		schema:             e.schema,
 		dialect:            e.dialect,
 		logger:             e.logger,
 		processedRelations: make(map[string]struct{}),
-	}
+	}, nil
 }
 
 func (e *Engine) newSanitizer() *sanitizer {
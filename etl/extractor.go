@@ -5,35 +5,73 @@ import (
 	"fmt"
 	"strings"
 
-	lk "github.com/ulule/loukoum/v3"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/ulule/mover/config"
 	"github.com/ulule/mover/dialect"
 )
 
-type (
-	resultSet []map[string]interface{}
-	extract   map[string]entry
-	entry     map[string]resultSet
-	extractor struct {
-		extract            extract
-		dialect            dialect.Dialect
-		schema             map[string]config.Schema
-		logger             *zap.Logger
-		processedRelations map[string]struct{}
-	}
+// maxFetchedKeys is the number of keys of a column fetched by a single query, composite keys are
+// fetched by smaller chunks since every key adds a condition to the query.
+const (
+	maxFetchedKeys          = 1000
+	maxFetchedCompositeKeys = 100
 )
 
+// defaultWorkers is the number of concurrent fetches of the extractor when the number of workers is not configured,
+// extractions are sequential unless workers are requested.
+const defaultWorkers = 1
+
+type extractor struct {
+	// spill contains the extracted rows, processedRelations, queries and requested are the only index kept in memory.
+	spill              *spill
+	queries            map[string]struct{}
+	dialect            dialect.Dialect
+	schema             map[string]config.Schema
+	logger             *zap.Logger
+	processedRelations map[string]struct{}
+	// pending are the fetches of the next level of the traversal, requested contains the relation keys
+	// of the rows already fetched or pending.
+	pending   []*pendingFetch
+	requested map[string]struct{}
+	// workers is the number of concurrent fetches. Workers only fetch and encode rows, the traversal state
+	// is only accessed by the goroutine running Handle.
+	workers int
+}
+
+// pendingFetch contains the values of the rows of a table to fetch on columns, or a query of the table.
+type pendingFetch struct {
+	tableName   string
+	columnNames []string
+	values      [][]interface{}
+	query       string
+	depth       int
+}
+
+// fetchJob is a query of the rows of a table run by a worker.
+type fetchJob struct {
+	tableName string
+	query     string
+	args      []interface{}
+	depth     int
+}
+
+// fetchResult contains the rows fetched by a job with their keys and spill lines, see encodeRows.
+type fetchResult struct {
+	rows  []map[string]interface{}
+	keys  []string
+	lines [][]byte
+}
+
 func depthF(depth int, msg string) string {
 	return strings.Repeat("\t", depth+1) + msg
 }
 
-func (e *extractor) handleReferenceKeys(ctx context.Context, depth int, table dialect.Table, row map[string]interface{}) error {
+// handleReferenceKeys enqueues the rows referencing a row and the rows of the queries of its table.
+func (e *extractor) handleReferenceKeys(depth int, table dialect.Table, row map[string]interface{}) {
 	var (
 		referenceKeys = make(dialect.ReferenceKeys, 0)
-		primaryKeys   = table.PrimaryKeys
-		primaryKey    = primaryKeys[0]
 		schema        = e.schema[table.Name]
 	)
 
@@ -49,22 +87,30 @@ func (e *extractor) handleReferenceKeys(ctx context.Context, depth int, table di
 		}
 	}
 
+	// Rows of a table without any key cannot be referenced.
+	if len(referenceKeys) > 0 && len(table.KeyColumnNames()) == 0 {
+		e.logger.Debug(depthF(depth+1, fmt.Sprintf("Skip reference keys of table %s without primary or unique key", table.Name)))
+		referenceKeys = nil
+	}
+
 	for i := range referenceKeys {
-		value := row[primaryKey.Name]
 		referenceKey := referenceKeys[i]
+		values := rowValues(row, referenceKey.Columns.ReferencedColumnNames())
 
-		query, args := lk.Select(lk.Raw("*")).
-			From(referenceKey.Table.Name).
-			Where(lk.Condition(referenceKey.ColumnName).Equal(value)).
-			Query()
+		if _, ok := e.schema[referenceKey.TableName]; !ok {
+			e.logger.Debug(depthF(depth+1, fmt.Sprintf("Skip reference key %s from table %s outside of selected schemas", referenceKey.Name, referenceKey.TableName)))
+			continue
+		}
+
+		if hasNil(values) {
+			continue
+		}
 
-		e.logger.Debug(depthF(depth+1, fmt.Sprintf("Fetch reference key %s = %v", referenceKey, value)),
+		e.logger.Debug(depthF(depth+1, fmt.Sprintf("Fetch reference key %s = %v", referenceKey, joinValues(values))),
 			zap.String("table_name", table.Name),
 		)
 
-		if _, err := e.handle(ctx, depth+2, e.schema[referenceKey.Table.Name], query, args...); err != nil {
-			return fmt.Errorf("unable to handle table %s (query: %s, args: %v): %w", referenceKey.Table.Name, query, args, err)
-		}
+		e.enqueue(depth+2, referenceKey.Table.Name, referenceKey.Columns.ColumnNames(), values)
 	}
 
 	for i := range schema.Queries {
@@ -73,102 +119,254 @@ func (e *extractor) handleReferenceKeys(ctx context.Context, depth int, table di
 		e.logger.Debug(depthF(depth+1, "Execute query"),
 			zap.String("query", exec))
 
-		if _, err := e.handle(ctx, depth+1, e.schema[query.Table.Name], exec); err != nil {
-			return fmt.Errorf("unable to handle table %s (query %s): %w", query.Table.Name, exec, err)
+		e.pending = append(e.pending, &pendingFetch{
+			tableName: query.Table.Name,
+			query:     exec,
+			depth:     depth + 1,
+		})
+	}
+}
+
+// handleRow spills a row identified by key and enqueues the rows it is related to.
+func (e *extractor) handleRow(depth int, table dialect.Table, row map[string]interface{}, key string, line []byte) error {
+	if _, ok := e.processedRelations[key]; ok {
+		e.logger.Debug(depthF(depth, fmt.Sprintf("Relation %s already processed", key)))
+		return nil
+	}
+
+	e.processedRelations[key] = struct{}{}
+	e.logger.Debug(depthF(depth, fmt.Sprintf("Retrieve relation %s", key)))
+
+	if err := e.spill.writeLine(table.Name, line); err != nil {
+		return err
+	}
+
+	for _, foreignKey := range table.ForeignKeys {
+		values := rowValues(row, foreignKey.Columns.ColumnNames())
+
+		// A foreign key with a NULL column does not reference any row.
+		if hasNil(values) {
+			continue
+		}
+
+		if _, ok := e.schema[foreignKey.ReferencedTableName]; !ok {
+			e.logger.Debug(depthF(depth, fmt.Sprintf("Skip foreign key %s to table %s outside of selected schemas", foreignKey.Name, foreignKey.ReferencedTableName)))
+			continue
+		}
+
+		referencedColumnNames := foreignKey.Columns.ReferencedColumnNames()
+		foreignRelationKey := relationKey(foreignKey.ReferencedTableName, referencedColumnNames, values...)
+
+		if _, ok := e.processedRelations[foreignRelationKey]; ok {
+			e.logger.Debug(depthF(depth, fmt.Sprintf("Foreign relation %s already processed", foreignRelationKey)))
+			continue
 		}
+
+		e.logger.Debug(depthF(depth+1, fmt.Sprintf("Fetch foreign key %s = %v", foreignKey, joinValues(values))))
+
+		e.enqueue(depth+2, foreignKey.ReferencedTable.Name, referencedColumnNames, values)
 	}
 
+	e.handleReferenceKeys(depth, table, row)
+
 	return nil
 }
 
-func (e *extractor) handleRow(ctx context.Context, depth int, table dialect.Table, row map[string]interface{}) error {
-	var (
-		primaryKeys = table.PrimaryKeys
-		foreignKeys = make(map[string]dialect.ForeignKey, len(table.ForeignKeys))
-	)
+// Handle extracts the rows of a query and the rows they are related to, rows are written to the spill files.
+// Related rows are fetched breadth-first: the keys referenced by a level of rows are fetched together, by
+// concurrent workers.
+func (e *extractor) Handle(ctx context.Context, schema config.Schema, query string, args ...interface{}) error {
+	jobs := []fetchJob{{tableName: schema.Table.Name, query: query, args: args}}
+	for len(jobs) > 0 {
+		if err := e.run(ctx, e.uncachedJobs(jobs)); err != nil {
+			return err
+		}
 
-	for i := range table.ForeignKeys {
-		foreignKeys[table.ForeignKeys[i].ColumnName] = table.ForeignKeys[i]
+		jobs = e.nextJobs()
 	}
 
-	primaryKey := primaryKeys[0]
+	return nil
+}
+
+// run runs jobs over the workers and handles their rows in job order, so extractions write the same
+// files whatever the number of workers. A worker slot is released once the rows of its job are handled,
+// at most workers results are held in memory.
+func (e *extractor) run(ctx context.Context, jobs []fetchJob) error {
+	workers := e.workers
+	if workers < 1 {
+		workers = 1
+	}
 
-	relationKey := fmt.Sprintf("%s = %v", primaryKey, row[primaryKey.Name])
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	if _, ok := e.processedRelations[relationKey]; ok {
-		e.logger.Debug(depthF(depth, fmt.Sprintf("Relation %s already processed", relationKey)))
-		return nil
+	var (
+		g, gctx   = errgroup.WithContext(ctx)
+		semaphore = make(chan struct{}, workers)
+		results   = make([]chan *fetchResult, len(jobs))
+	)
+	for i := range results {
+		results[i] = make(chan *fetchResult, 1)
 	}
 
-	e.processedRelations[relationKey] = struct{}{}
-	e.logger.Debug(depthF(depth, fmt.Sprintf("Retrieve relation %s", relationKey)))
+	g.Go(func() error {
+		for i := range jobs {
+			select {
+			case semaphore <- struct{}{}:
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+
+			i, job := i, jobs[i]
+			g.Go(func() error {
+				fetched, err := e.fetch(gctx, job)
+				if err != nil {
+					return fmt.Errorf("unable to handle table %s (query %s): %w", job.tableName, job.query, err)
+				}
 
-	for k, v := range row {
-		if v == nil {
-			continue
+				results[i] <- fetched
+				return nil
+			})
 		}
 
-		if foreignKey, ok := foreignKeys[k]; ok {
-			foreignRelationKey := fmt.Sprintf("%s = %v", foreignKey, v)
+		return nil
+	})
 
-			if _, ok := e.processedRelations[foreignRelationKey]; ok {
-				e.logger.Debug(depthF(depth, fmt.Sprintf("Foreign relation %s already processed", foreignRelationKey)))
-				continue
-			}
+	for i := range jobs {
+		select {
+		case fetched := <-results[i]:
+			<-semaphore
 
-			e.logger.Debug(depthF(depth+1, fmt.Sprintf("Fetch foreign key %s = %v", foreignKey, v)))
-			query, args := lk.Select(lk.Raw("*")).
-				From(foreignKey.ReferencedTable.Name).
-				Where(lk.Condition(foreignKey.ReferencedColumnName).Equal(v)).
-				Query()
+			if err := e.handleRows(jobs[i], fetched); err != nil {
+				cancel()
+				//nolint:errcheck
+				g.Wait()
 
-			if _, err := e.handle(ctx, depth+2, e.schema[foreignKey.ReferencedTable.Name], query, args...); err != nil {
-				return fmt.Errorf("unable to handle table %s from foreign key %s: %w", foreignKey.ReferencedTable.Name, foreignKey.Name, err)
+				return fmt.Errorf("unable to handle table %s (query %s): %w", jobs[i].tableName, jobs[i].query, err)
 			}
+		case <-gctx.Done():
+			return g.Wait()
 		}
 	}
 
-	if err := e.handleReferenceKeys(ctx, depth, table, row); err != nil {
-		return err
+	return g.Wait()
+}
+
+// uncachedJobs returns the jobs whose query has not been run yet.
+func (e *extractor) uncachedJobs(jobs []fetchJob) []fetchJob {
+	uncached := make([]fetchJob, 0, len(jobs))
+	for _, job := range jobs {
+		cacheKey := job.tableName + ":" + cacheKey(job.query, job.args)
+		if _, ok := e.queries[cacheKey]; ok {
+			e.logger.Debug(depthF(job.depth, "Already cached"))
+			continue
+		}
+		e.queries[cacheKey] = struct{}{}
+
+		uncached = append(uncached, job)
 	}
 
-	return nil
+	return uncached
 }
 
-func (e *extractor) Handle(ctx context.Context, schema config.Schema, query string, args ...interface{}) (extract, error) {
-	return e.handle(ctx, 0, schema, query, args...)
+// enqueue adds the values of the rows of a table to fetch on columns to the next level of the traversal.
+func (e *extractor) enqueue(depth int, tableName string, columnNames []string, values []interface{}) {
+	key := relationKey(tableName, columnNames, values...)
+	if _, ok := e.requested[key]; ok {
+		e.logger.Debug(depthF(depth, fmt.Sprintf("Relation %s already requested", key)))
+		return
+	}
+	e.requested[key] = struct{}{}
+
+	for _, fetch := range e.pending {
+		if fetch.query == "" && fetch.tableName == tableName && strings.Join(fetch.columnNames, ",") == strings.Join(columnNames, ",") {
+			fetch.values = append(fetch.values, values)
+			return
+		}
+	}
+
+	e.pending = append(e.pending, &pendingFetch{
+		tableName:   tableName,
+		columnNames: columnNames,
+		values:      [][]interface{}{values},
+		depth:       depth,
+	})
 }
 
-func (e *extractor) handle(ctx context.Context, depth int, schema config.Schema, query string, args ...interface{}) (extract, error) {
-	var (
-		table     = schema.Table
-		tableName = table.Name
-		cacheKey  = cacheKey(query, args)
-	)
+// nextJobs returns the jobs of the next level of the traversal, pending keys are fetched by chunks.
+func (e *extractor) nextJobs() []fetchJob {
+	binder, ok := e.dialect.(dialect.ArrayBinder)
+	arrays := ok && binder.BindsArrays()
 
-	if _, ok := e.extract[tableName]; !ok {
-		e.extract[tableName] = make(entry)
-	}
+	jobs := make([]fetchJob, 0, len(e.pending))
+	for _, fetch := range e.pending {
+		if fetch.query != "" {
+			jobs = append(jobs, fetchJob{tableName: fetch.tableName, query: fetch.query, depth: fetch.depth})
+			continue
+		}
+
+		chunkSize := maxFetchedKeys
+		if len(fetch.columnNames) > 1 {
+			chunkSize = maxFetchedCompositeKeys
+		}
 
-	if _, ok := e.extract[tableName][cacheKey]; ok {
-		e.logger.Debug(depthF(depth, "Already cached"))
-		return nil, nil
+		for i := 0; i < len(fetch.values); i += chunkSize {
+			end := i + chunkSize
+			if end > len(fetch.values) {
+				end = len(fetch.values)
+			}
+
+			e.logger.Debug(depthF(fetch.depth, fmt.Sprintf("Fetch %d keys of %s(%s)", end-i, fetch.tableName, strings.Join(fetch.columnNames, ", "))))
+
+			query, args := selectRowsIn(fetch.tableName, fetch.columnNames, fetch.values[i:end], arrays)
+			jobs = append(jobs, fetchJob{tableName: fetch.tableName, query: query, args: args, depth: fetch.depth})
+		}
 	}
+	e.pending = nil
+
+	return jobs
+}
 
-	results, err := e.dialect.ResultSet(ctx, query, args...)
+// fetch runs the query of a job and encodes its rows, it is run by the workers.
+func (e *extractor) fetch(ctx context.Context, job fetchJob) (*fetchResult, error) {
+	results, err := e.dialect.ResultSet(ctx, job.query, job.args...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to retrieve results: %w", err)
 	}
 
-	e.logger.Debug(depthF(depth, fmt.Sprintf("-> %d results", len(results))))
+	e.logger.Debug(depthF(job.depth, fmt.Sprintf("-> %d results", len(results))))
 
-	e.extract[tableName][cacheKey] = results
+	return encodeRows(e.schema[job.tableName].Table, results)
+}
 
-	for i := range results {
-		if err := e.handleRow(ctx, depth, table, results[i]); err != nil {
-			return nil, fmt.Errorf("unable to handle row %v from table %s: %w", table, results[i], err)
+// encodeRows returns the rows of a table with their keys and spill lines.
+func encodeRows(table dialect.Table, rows []map[string]interface{}) (*fetchResult, error) {
+	fetched := &fetchResult{
+		rows:  rows,
+		keys:  make([]string, len(rows)),
+		lines: make([][]byte, len(rows)),
+	}
+	for i := range rows {
+		line, err := encodeLine(table.Name, rows[i])
+		if err != nil {
+			return nil, err
 		}
+
+		fetched.keys[i] = rowKey(table, rows[i])
+		fetched.lines[i] = line
 	}
 
-	return e.extract, nil
+	return fetched, nil
+}
+
+// handleRows handles the rows fetched by a job.
+func (e *extractor) handleRows(job fetchJob, fetched *fetchResult) error {
+	table := e.schema[job.tableName].Table
+	for i := range fetched.rows {
+		if err := e.handleRow(job.depth, table, fetched.rows[i], fetched.keys[i], fetched.lines[i]); err != nil {
+			return fmt.Errorf("unable to handle row %v from table %s: %w", fetched.rows[i], table.Name, err)
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,341 @@
+package etl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect"
+	"github.com/ulule/mover/dialect/memory"
+)
+
+func newTestEngine(t *testing.T, fixtureName string, cfg config.Config) (*Engine, *memory.MemoryDialect) {
+	fixture, err := memory.LoadFixture(filepath.Join("testdata", fixtureName))
+	require.NoError(t, err)
+
+	d, err := fixture.Dialect()
+	require.NoError(t, err)
+
+	engine, err := NewEngineWithDialect(context.Background(), cfg, d, zap.NewNop())
+	require.NoError(t, err)
+
+	return engine, d
+}
+
+func readPayload(t *testing.T, filePath string) jsonPayload {
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+
+	var payload jsonPayload
+	require.NoError(t, json.Unmarshal(content, &payload))
+
+	return payload
+}
+
+func payloadIDs(payload jsonPayload) []float64 {
+	ids := make([]float64, len(payload.Data))
+	for i := range payload.Data {
+		ids[i] = payload.Data[i]["id"].(float64)
+	}
+	sort.Float64s(ids)
+
+	return ids
+}
+
+func TestExtractorHandle(t *testing.T) {
+	engine, d := newTestEngine(t, "fixture.json", config.Config{})
+
+	extractor, err := engine.newExtractor(t.TempDir())
+	require.NoError(t, err)
+	defer extractor.spill.Close()
+
+	require.NoError(t, extractor.Handle(context.Background(), engine.schema["project"], "SELECT * FROM project"))
+
+	// Projects reference their owners through foreign keys and are referenced by rewards.
+	assert.Equal(t, 2, extractor.spill.count("project"))
+	assert.Equal(t, 2, extractor.spill.count("user"))
+	assert.Equal(t, 3, extractor.spill.count("reward"))
+
+	_, ok := extractor.processedRelations["user(id) = 1"]
+	assert.True(t, ok)
+	_, ok = extractor.processedRelations["user(id) = 3"]
+	assert.False(t, ok)
+
+	queries := make(map[string]int)
+	for _, query := range d.Queries() {
+		queries[cacheKey(query.Query, query.Args...)]++
+	}
+	for query, count := range queries {
+		assert.Equal(t, 1, count, query)
+	}
+
+	// The owners and the rewards of both projects are fetched by a single query, concurrently.
+	assert.ElementsMatch(t, []memory.Query{
+		{Query: "SELECT * FROM project"},
+		{Query: `SELECT * FROM "user" WHERE ("id" = ANY($1))`, Args: []interface{}{[]interface{}{float64(1), float64(2)}}},
+		{Query: `SELECT * FROM "reward" WHERE ("project_id" = ANY($1))`, Args: []interface{}{[]interface{}{float64(1), float64(2)}}},
+	}, d.Queries())
+}
+
+func TestExtract(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	engine, d := newTestEngine(t, "fixture.json", config.Config{})
+
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM user WHERE id = 1"))
+
+	assert.Equal(t, []float64{1}, payloadIDs(readPayload(t, filepath.Join(outputPath, "user.json"))))
+	assert.Equal(t, []float64{1}, payloadIDs(readPayload(t, filepath.Join(outputPath, "project.json"))))
+
+	// Reference keys are only followed from the extracted rows.
+	_, err := os.Stat(filepath.Join(outputPath, "reward.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Spill files are removed once the rows are written.
+	entries, err := os.ReadDir(outputPath)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// The owner of the project has already been processed from the root query.
+	for _, query := range d.Queries()[1:] {
+		assert.NotContains(t, query.Query, `FROM "user"`)
+	}
+}
+
+func TestExtractReferenceKeys(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	engine, _ := newTestEngine(t, "fixture.json", config.Config{
+		Schema: []config.Schema{
+			{
+				TableName:     "project",
+				ReferenceKeys: []string{"reward_project_id_fkey"},
+			},
+		},
+	})
+
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM user WHERE id = 2"))
+
+	assert.Equal(t, []float64{2}, payloadIDs(readPayload(t, filepath.Join(outputPath, "project.json"))))
+	assert.Equal(t, []float64{3}, payloadIDs(readPayload(t, filepath.Join(outputPath, "reward.json"))))
+}
+
+func TestExtractSchemas(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	engine, _ := newTestEngine(t, "schemas.json", config.Config{
+		Schemas: []string{"public", "billing"},
+	})
+
+	_, err := engine.Describe(ctx, "auth.token")
+	assert.Error(t, err)
+
+	require.NoError(t, engine.Extract(ctx, outputPath, `SELECT * FROM "billing"."invoice" WHERE id = 1`))
+
+	payload := readPayload(t, filepath.Join(outputPath, "billing.invoice.json"))
+	assert.Equal(t, "billing.invoice", payload.TableName)
+	assert.Equal(t, []float64{1}, payloadIDs(payload))
+	assert.Equal(t, []float64{1}, payloadIDs(readPayload(t, filepath.Join(outputPath, "user.json"))))
+
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM user WHERE id = 1"))
+
+	// Tokens belong to a schema which is not selected.
+	assert.Equal(t, []float64{1, 2}, payloadIDs(readPayload(t, filepath.Join(outputPath, "billing.invoice.json"))))
+	_, err = os.Stat(filepath.Join(outputPath, "auth.token.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestExtractCompositeKeys(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	engine, _ := newTestEngine(t, "backers.json", config.Config{})
+
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM user WHERE id = 3"))
+
+	// Backers are only identified by the (user_id, project_id) pair.
+	backers := readPayload(t, filepath.Join(outputPath, "backer.json"))
+	require.Len(t, backers.Data, 2)
+	assert.ElementsMatch(t, []float64{1, 2}, []float64{
+		backers.Data[0]["project_id"].(float64),
+		backers.Data[1]["project_id"].(float64),
+	})
+
+	assert.Equal(t, []float64{1, 2}, payloadIDs(readPayload(t, filepath.Join(outputPath, "project.json"))))
+	assert.Equal(t, []float64{1, 2, 3}, payloadIDs(readPayload(t, filepath.Join(outputPath, "user.json"))))
+}
+
+func TestExtractorHandleCompositeKeys(t *testing.T) {
+	engine, _ := newTestEngine(t, "backers.json", config.Config{})
+
+	extractor, err := engine.newExtractor(t.TempDir())
+	require.NoError(t, err)
+	defer extractor.spill.Close()
+
+	backer := engine.schema["backer"].Table
+	handle := func(rows ...map[string]interface{}) {
+		fetched, err := encodeRows(backer, rows)
+		require.NoError(t, err)
+		require.NoError(t, extractor.handleRows(fetchJob{tableName: "backer"}, fetched))
+	}
+
+	// Rows fetched by several queries are spilled once per composite key.
+	handle(
+		map[string]interface{}{"user_id": 3, "project_id": 1, "amount": 10},
+		map[string]interface{}{"user_id": 3, "project_id": 2, "amount": 20},
+	)
+	handle(map[string]interface{}{"user_id": 3, "project_id": 2, "amount": 20})
+	assert.Equal(t, 2, extractor.spill.count("backer"))
+
+	// Rows with a NULL key column are identified by a hash of all their values.
+	row := map[string]interface{}{"user_id": nil, "project_id": 1, "amount": 10}
+	assert.True(t, strings.HasPrefix(rowKey(backer, row), "backer(*) = "))
+
+	handle(row, map[string]interface{}{"user_id": nil, "project_id": 1, "amount": 30})
+	handle(map[string]interface{}{"user_id": nil, "project_id": 1, "amount": 10})
+	assert.Equal(t, 4, extractor.spill.count("backer"))
+
+	_, ok := extractor.processedRelations[rowKey(backer, row)]
+	assert.True(t, ok)
+}
+
+func TestExtractCompositeForeignKeys(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	engine, d := newTestEngine(t, "backers.json", config.Config{})
+
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM contribution WHERE id IN (1, 3)"))
+
+	// Contributions reference a backer through the (user_id, project_id) pair,
+	// a NULL column does not reference any backer.
+	backers := readPayload(t, filepath.Join(outputPath, "backer.json"))
+	require.Len(t, backers.Data, 1)
+	assert.Equal(t, float64(3), backers.Data[0]["user_id"])
+	assert.Equal(t, float64(1), backers.Data[0]["project_id"])
+
+	assert.Equal(t, []float64{1}, payloadIDs(readPayload(t, filepath.Join(outputPath, "project.json"))))
+	assert.Equal(t, []float64{1, 3}, payloadIDs(readPayload(t, filepath.Join(outputPath, "user.json"))))
+
+	assert.Contains(t, d.Queries(), memory.Query{
+		Query: `SELECT * FROM "backer" WHERE (("user_id" = $1) AND ("project_id" = $2))`,
+		Args:  []interface{}{float64(3), float64(1)},
+	})
+
+	outputPath = t.TempDir()
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM backer WHERE user_id = 1"))
+
+	// Reference keys are followed from the referenced columns of the backer.
+	assert.Equal(t, []float64{2}, payloadIDs(readPayload(t, filepath.Join(outputPath, "contribution.json"))))
+}
+
+func TestExtractWithoutKeys(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	engine, _ := newTestEngine(t, "backers.json", config.Config{})
+
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM log WHERE user_id = 3"))
+
+	// Rows without any key are deduplicated on all their values.
+	logs := readPayload(t, filepath.Join(outputPath, "log.json"))
+	assert.Len(t, logs.Data, 2)
+
+	assert.Equal(t, []float64{3}, payloadIDs(readPayload(t, filepath.Join(outputPath, "user.json"))))
+}
+
+func TestExtractViews(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	cfg := config.Config{
+		Schema: []config.Schema{
+			{
+				TableName:   "project_stats",
+				PrimaryKeys: []string{"project_id"},
+				ForeignKeys: []config.ForeignKey{
+					{ColumnNames: []string{"project_id"}, ReferencedTableName: "project", ReferencedColumnNames: []string{"id"}},
+				},
+			},
+		},
+	}
+
+	engine, _ := newTestEngine(t, "views.json", cfg)
+
+	table, err := engine.Describe(ctx, "project_stats")
+	require.NoError(t, err)
+	assert.Equal(t, dialect.TableKindView, table.Kind)
+	assert.Equal(t, []string{"project_id"}, table.PrimaryKeyColumnNames())
+	require.Len(t, table.ForeignKeys, 1)
+	assert.Equal(t, "project_stats_project_id_fkey", table.ForeignKeys[0].Name)
+	assert.Equal(t, "project", table.ForeignKeys[0].ReferencedTable.Name)
+
+	// Views are traversed through their virtual keys.
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM project_stats WHERE user_id = 1"))
+	assert.Len(t, readPayload(t, filepath.Join(outputPath, "project_stats.json")).Data, 1)
+	assert.Equal(t, []float64{1}, payloadIDs(readPayload(t, filepath.Join(outputPath, "project.json"))))
+	assert.Equal(t, []float64{1}, payloadIDs(readPayload(t, filepath.Join(outputPath, "user.json"))))
+
+	cfg.Schema[0].ForeignKeys[0].ReferencedTableName = "unknown"
+	fixture, err := memory.LoadFixture(filepath.Join("testdata", "views.json"))
+	require.NoError(t, err)
+	d, err := fixture.Dialect()
+	require.NoError(t, err)
+	_, err = NewEngineWithDialect(ctx, cfg, d, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestExtractWorkers(t *testing.T) {
+	ctx := context.Background()
+
+	engine, d := newTestEngine(t, "backers.json", config.Config{Workers: 4})
+	outputPath := t.TempDir()
+	require.NoError(t, engine.Extract(ctx, outputPath, "SELECT * FROM user WHERE id = 3"))
+
+	// Workers share a snapshot of the database.
+	assert.Equal(t, []int{4}, d.Snapshots())
+	assert.Equal(t, []float64{1, 2}, payloadIDs(readPayload(t, filepath.Join(outputPath, "project.json"))))
+	assert.Equal(t, []float64{1, 2, 3}, payloadIDs(readPayload(t, filepath.Join(outputPath, "user.json"))))
+
+	// Extractions are sequential by default.
+	engine, d = newTestEngine(t, "backers.json", config.Config{})
+	sequentialPath := t.TempDir()
+	require.NoError(t, engine.Extract(ctx, sequentialPath, "SELECT * FROM user WHERE id = 3"))
+	assert.Empty(t, d.Snapshots())
+
+	// Rows are handled in job order, files are the same whatever the number of workers.
+	for _, fileName := range []string{"backer.json", "project.json", "user.json"} {
+		assert.Equal(t,
+			readPayload(t, filepath.Join(sequentialPath, fileName)).Data,
+			readPayload(t, filepath.Join(outputPath, fileName)).Data, fileName)
+	}
+
+	_, err := NewEngineWithDialect(ctx, config.Config{Workers: -1}, d, zap.NewNop())
+	assert.Error(t, err)
+}
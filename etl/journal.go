@@ -0,0 +1,121 @@
+package etl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// journalFileName is the name of the journal written in the output directory by default,
+// its extension differs from extensionFormat so it is never loaded.
+const journalFileName = "journal.jsonl"
+
+// journalEntry contains the keys of the rows inserted in a table by a load.
+type journalEntry struct {
+	TableName   string          `json:"table_name"`
+	ColumnNames []string        `json:"column_names"`
+	Keys        [][]interface{} `json:"keys"`
+}
+
+// journal records the rows inserted by a load so they can be unloaded, entries are appended
+// to the journal file in load order.
+type journal struct {
+	path string
+	// buffered keeps the entries in memory until write is called, for loads committed as a whole.
+	buffered bool
+	entries  []journalEntry
+	// written counts the entries appended to the journal file.
+	written int
+}
+
+// journalPath returns the path of the journal of an output directory.
+func journalPath(path string, outputPath string) string {
+	if path != "" {
+		return path
+	}
+
+	return filepath.Join(outputPath, journalFileName)
+}
+
+// record records the keys of rows inserted in a table once they are committed, the entry is appended
+// to the journal file at once unless the journal is buffered so a load interrupted midway keeps the
+// journal of its committed batches.
+func (j *journal) record(tableName string, columnNames []string, keys [][]interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	j.entries = append(j.entries, journalEntry{
+		TableName:   tableName,
+		ColumnNames: columnNames,
+		Keys:        keys,
+	})
+
+	if j.buffered {
+		return nil
+	}
+
+	return j.write()
+}
+
+// write appends the recorded entries to the journal file, one JSON entry per line.
+func (j *journal) write() error {
+	if len(j.entries) == 0 {
+		return nil
+	}
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open journal %s: %w", j.path, err)
+	}
+
+	encoder := json.NewEncoder(file)
+	for i := range j.entries {
+		if err := encoder.Encode(j.entries[i]); err != nil {
+			file.Close()
+			return fmt.Errorf("unable to write journal %s: %w", j.path, err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to write journal %s: %w", j.path, err)
+	}
+
+	j.written += len(j.entries)
+	j.entries = nil
+
+	return nil
+}
+
+// readJournal reads the entries of a journal file in load order.
+func readJournal(path string) ([]journalEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open journal %s: %w", path, err)
+	}
+	defer file.Close()
+
+	entries := make([]journalEntry, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("unable to decode journal %s: %w", path, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read journal %s: %w", path, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,85 @@
+package etl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/config"
+)
+
+func TestUnload(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 1, "username": "conflict", "email": "conflict@ulule.com"},
+			{"id": 4, "username": "loader", "email": "loader@ulule.com"},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 3, "name": "loader", "user_id": 4},
+		},
+	})
+
+	engine, d := newTestEngine(t, "fixture.json", config.Config{})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	require.Len(t, d.Rows("user"), 4)
+
+	// The conflicting user is skipped and not journaled.
+	entries, err := readJournal(filepath.Join(outputPath, journalFileName))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, journalEntry{TableName: "user", ColumnNames: []string{"id"}, Keys: [][]interface{}{{float64(4)}}}, entries[0])
+	assert.Equal(t, journalEntry{TableName: "project", ColumnNames: []string{"id"}, Keys: [][]interface{}{{float64(3)}}}, entries[1])
+
+	require.NoError(t, engine.Unload(ctx, outputPath))
+	assert.Equal(t, []string{"project", "user"}, d.Deletes())
+	assert.Len(t, d.Rows("user"), 3)
+	assert.Equal(t, "thoas", d.Rows("user")[0]["username"])
+	assert.Len(t, d.Rows("project"), 2)
+
+	_, err = os.Stat(filepath.Join(outputPath, journalFileName))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, engine.Unload(ctx, outputPath))
+}
+
+func TestJournalRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), journalFileName)
+
+	// Entries are written as soon as they are recorded.
+	j := &journal{path: path}
+	require.NoError(t, j.record("user", []string{"id"}, [][]interface{}{{float64(4)}}))
+	require.NoError(t, j.record("user", []string{"id"}, nil))
+
+	entries, err := readJournal(path)
+	require.NoError(t, err)
+	assert.Equal(t, []journalEntry{{TableName: "user", ColumnNames: []string{"id"}, Keys: [][]interface{}{{float64(4)}}}}, entries)
+
+	// Buffered entries are written by write.
+	j = &journal{path: path, buffered: true}
+	require.NoError(t, j.record("project", []string{"id"}, [][]interface{}{{float64(3)}}))
+
+	entries, err = readJournal(path)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, j.write())
+	assert.Equal(t, 1, j.written)
+
+	entries, err = readJournal(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "project", entries[1].TableName)
+}
@@ -2,11 +2,11 @@ package etl
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"go.uber.org/zap"
@@ -19,16 +19,32 @@ type loader struct {
 	dialect dialect.Dialect
 	schema  map[string]config.Schema
 	logger  *zap.Logger
+	// conflict is the conflict strategy of tables without their own strategy.
+	conflict dialect.ConflictStrategy
+	// mode is the load mode of dialects implementing dialect.LoadSession.
+	mode dialect.LoadMode
+	// atomic loads every file in a single transaction of dialects implementing dialect.LoadSession.
+	atomic bool
+	// remap loads rows with keys allocated from the target sequences, see allocateKeys.
+	remap bool
+	// journalPath is the path of the journal, see journalPath.
+	journalPath string
+	// journal records the inserted rows of dialects implementing dialect.Unloader.
+	journal *journal
+	// verify defines how rows referencing rows which do not exist are handled once loaded.
+	verify config.VerifyMode
+	// batchSize is the number of rows decoded and inserted at once, see streamFile.
+	batchSize int
+	// dryRun reports what the load would change without writing anything, see report.
+	dryRun bool
 }
 
-// Load loads data from an output directory.
-func (l *loader) Load(ctx context.Context, outputPath string) error {
+// listFiles returns the files of an output directory.
+func listFiles(outputPath string) ([]string, error) {
 	var files []string
 
-	l.logger.Info("Loading files from directory", zap.String("output_path", outputPath))
-
 	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
-		return fmt.Errorf("unable to open directory %s: %w", outputPath, err)
+		return nil, fmt.Errorf("unable to open directory %s: %w", outputPath, err)
 	}
 
 	if err := filepath.Walk(outputPath, func(path string, info os.FileInfo, err error) error {
@@ -37,40 +53,692 @@ func (l *loader) Load(ctx context.Context, outputPath string) error {
 		}
 		return nil
 	}); err != nil {
-		return fmt.Errorf("unable to walk path %s: %w", outputPath, err)
+		return nil, fmt.Errorf("unable to walk path %s: %w", outputPath, err)
+	}
+
+	return files, nil
+}
+
+// Load loads data from an output directory.
+func (l *loader) Load(ctx context.Context, outputPath string) error {
+	l.logger.Info("Loading files from directory", zap.String("output_path", outputPath))
+
+	files, err := listFiles(outputPath)
+	if err != nil {
+		return err
+	}
+
+	files = l.sortFiles(files)
+
+	if l.dryRun {
+		_, err := l.report(ctx, files)
+		return err
+	}
+
+	if _, ok := l.dialect.(dialect.Unloader); ok {
+		l.journal = &journal{path: journalPath(l.journalPath, outputPath)}
+	}
+
+	session, ok := l.dialect.(dialect.LoadSession)
+	if !ok {
+		err := l.loadFiles(ctx, files, "")
+		if jerr := l.writeJournal(); jerr != nil && err == nil {
+			err = jerr
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return l.refreshMaterializedViews(ctx)
+	}
+
+	tableNames := make([]string, len(files))
+	for i := range files {
+		tableNames[i] = fileTableName(files[i])
+	}
+
+	mode, err := session.BeginLoad(ctx, dialect.LoadOptions{Mode: l.mode, Atomic: l.atomic, Tables: l.tables(tableNames)})
+	if err != nil {
+		return fmt.Errorf("unable to begin load: %w", err)
+	}
+
+	l.logger.Info("Load mode", zap.String("mode", string(mode)), zap.Bool("atomic", l.atomic))
+
+	// Atomic and deferred loads are committed as a whole, their entries are written once the load is committed.
+	if l.journal != nil {
+		l.journal.buffered = l.atomic || mode == dialect.LoadModeDeferred
+	}
+
+	err = l.loadFiles(ctx, files, mode)
+	if endErr := session.EndLoad(ctx, err); endErr != nil && err == nil {
+		err = fmt.Errorf("unable to end load: %w", endErr)
+	}
+
+	// Atomic and deferred loads are rolled back as a whole, nothing is journaled.
+	if err != nil && (l.atomic || mode == dialect.LoadModeDeferred) {
+		l.logger.Error("Load rolled back, no table has been loaded", zap.Error(err))
+
+		return err
+	}
+
+	if jerr := l.writeJournal(); jerr != nil && err == nil {
+		err = jerr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return l.refreshMaterializedViews(ctx)
+}
+
+// writeJournal appends the buffered entries of the load to the journal.
+func (l *loader) writeJournal() error {
+	if l.journal == nil {
+		return nil
+	}
+
+	if err := l.journal.write(); err != nil {
+		return err
+	}
+
+	l.logger.Info("Journal written", zap.String("path", l.journal.path), zap.Int("entries", l.journal.written))
+
+	return nil
+}
+
+// Unload deletes the rows inserted by the loads journaled in an output directory, in reverse
+// dependency order. The journal is removed once every row is deleted.
+func (l *loader) Unload(ctx context.Context, outputPath string) error {
+	unloader, ok := l.dialect.(dialect.Unloader)
+	if !ok {
+		return fmt.Errorf("unable to unload: dialect does not journal loaded rows")
+	}
+
+	path := journalPath(l.journalPath, outputPath)
+	entries, err := readJournal(path)
+	if err != nil {
+		return err
+	}
+
+	l.logger.Info("Unloading rows from journal", zap.String("path", path), zap.Int("tables", len(entries)))
+
+	session, ok := l.dialect.(dialect.LoadSession)
+	if !ok {
+		if err := l.unloadEntries(ctx, unloader, entries); err != nil {
+			return err
+		}
+
+		return os.Remove(path)
+	}
+
+	tableNames := make([]string, len(entries))
+	for i := range entries {
+		tableNames[i] = entries[i].TableName
+	}
+
+	if _, err := session.BeginLoad(ctx, dialect.LoadOptions{Mode: l.mode, Atomic: l.atomic, Tables: l.tables(tableNames)}); err != nil {
+		return fmt.Errorf("unable to begin unload: %w", err)
+	}
+
+	err = l.unloadEntries(ctx, unloader, entries)
+	if endErr := session.EndLoad(ctx, err); endErr != nil && err == nil {
+		err = fmt.Errorf("unable to end unload: %w", endErr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// tables returns the tables of the schema, unknown tables are ignored.
+func (l *loader) tables(tableNames []string) dialect.Tables {
+	tables := make(dialect.Tables, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		if schema, ok := l.schema[tableName]; ok {
+			tables = append(tables, schema.Table)
+		}
+	}
+
+	return tables
+}
+
+func (l *loader) unloadEntries(ctx context.Context, unloader dialect.Unloader, entries []journalEntry) error {
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+
+		schema, ok := l.schema[entry.TableName]
+		if !ok {
+			return fmt.Errorf("unable to unload %s: table does not exist", entry.TableName)
+		}
+
+		if keyColumnNames := schema.Table.KeyColumnNames(); strings.Join(keyColumnNames, ",") != strings.Join(entry.ColumnNames, ",") {
+			return fmt.Errorf("unable to unload %s: rows are journaled by (%s) instead of (%s)",
+				entry.TableName, strings.Join(entry.ColumnNames, ", "), strings.Join(keyColumnNames, ", "))
+		}
+
+		l.logger.Info("Unload table", zap.String("table", entry.TableName), zap.Int("count", len(entry.Keys)))
+
+		if err := unloader.BulkDelete(ctx, schema.Table, entry.Keys); err != nil {
+			return fmt.Errorf("unable to unload %s: %w", entry.TableName, err)
+		}
+	}
+
+	return nil
+}
+
+// loadFiles loads files in order. When constraints are checked while loading, the nullable foreign key
+// columns referencing rows which are not loaded yet are loaded as NULL and set once every file is loaded.
+func (l *loader) loadFiles(ctx context.Context, files []string, mode dialect.LoadMode) error {
+	tableNames := make([]string, len(files))
+	for i := range files {
+		tableNames[i] = fileTableName(files[i])
+	}
+
+	var deferred map[string][]string
+	updater, ok := l.dialect.(dialect.Updater)
+	if ok && (mode == dialect.LoadModeOrdered || mode == dialect.LoadModeDeferred) {
+		deferred = deferredColumns(tableNames, l.schema)
+	}
+
+	// The auto mode falls back to the ordered mode when constraints cannot be deferred, rows referencing
+	// a table loaded later would fail to load unless their columns are deferred.
+	if mode == dialect.LoadModeOrdered && (l.mode == "" || l.mode == dialect.LoadModeAuto) {
+		if columnNames := forwardReferences(tableNames, l.schema, deferred); len(columnNames) > 0 {
+			return fmt.Errorf("unable to load in ordered mode, constraints are not deferrable and %s reference tables loaded later: "+
+				"declare the foreign keys as DEFERRABLE or select a load mode", strings.Join(columnNames, ", "))
+		}
+	}
+
+	var mapping keyMapping
+	if l.remap {
+		var err error
+		if mapping, err = l.allocateKeys(ctx, files); err != nil {
+			return err
+		}
+	}
+
+	// Deferred rows are spilled to temporary files until every file is loaded so memory stays bounded.
+	var updates *spill
+	if len(deferred) > 0 {
+		var err error
+		if updates, err = newSpill("", false); err != nil {
+			return err
+		}
+		defer func() {
+			if err := updates.Close(); err != nil {
+				l.logger.Error("unable to remove spill files", zap.Error(err))
+			}
+		}()
 	}
 
 	for _, file := range files {
 		l.logger.Info("Load file", zap.String("file", file))
 
-		if err := l.loadFile(ctx, file); err != nil {
+		if schema := l.schema[fileTableName(file)]; !schema.Table.Insertable() {
+			l.logger.Info("Skip table which is not insertable",
+				zap.String("table", fileTableName(file)),
+				zap.String("kind", string(schema.Table.Kind)))
+			continue
+		}
+
+		// Atomic and deferred loads roll back the batches inserted before a conflict on their own.
+		if l.conflictStrategy(l.schema[fileTableName(file)]) == dialect.ConflictFail && !l.atomic && mode != dialect.LoadModeDeferred {
+			if err := l.conflicts(ctx, file); err != nil {
+				return fmt.Errorf("unable to load file %s: %w", file, err)
+			}
+		}
+
+		// Rows are loaded in batches, offset is the index of the first row of a batch in the file.
+		offset := 0
+		if err := streamFile(file, l.batchSize, func(tableName string, rows []map[string]interface{}) error {
+			schema := l.schema[tableName]
+			if mapping != nil {
+				remapRows(l.schema, schema.Table, rows, mapping)
+			}
+
+			if columnNames := deferred[tableName]; len(columnNames) > 0 {
+				var deferredRows []map[string]interface{}
+				rows, deferredRows = splitDeferred(schema.Table, rows, columnNames)
+				for i := range deferredRows {
+					if err := updates.write(tableName, deferredRows[i]); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := l.loadJSON(ctx, schema, jsonPayload{TableName: tableName, Data: rows}, offset); err != nil {
+				// Every batch is committed on its own unless the load is rolled back as a whole.
+				if offset > 0 && !l.atomic && mode != dialect.LoadModeDeferred {
+					l.logger.Error("Rows of previous batches are kept",
+						zap.String("table", tableName),
+						zap.Int("count", offset))
+				}
+
+				return err
+			}
+			offset += len(rows)
+
+			return nil
+		}); err != nil {
 			return fmt.Errorf("unable to load file %s: %w", file, err)
 		}
 	}
 
+	batchSize := l.batchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	for _, tableName := range tableNames {
+		if updates == nil || updates.count(tableName) == 0 {
+			continue
+		}
+
+		l.logger.Info("Update deferred foreign keys",
+			zap.String("table", tableName),
+			zap.Strings("columns", deferred[tableName]),
+			zap.Int("count", updates.count(tableName)))
+
+		if err := updates.readBatches(tableName, batchSize, func(rows []map[string]interface{}) error {
+			return updater.BulkUpdate(ctx, l.schema[tableName].Table, rows)
+		}); err != nil {
+			return fmt.Errorf("unable to update deferred foreign keys of %s: %w", tableName, err)
+		}
+	}
+
+	if l.verify == config.VerifyOff {
+		return nil
+	}
+
+	// Strict loads fail before the end of the load so atomic and deferred loads are rolled back.
+	references, err := l.verifyTables(ctx, tableNames)
+	if err != nil {
+		return err
+	}
+
+	if len(references) > 0 && l.verify == config.VerifyStrict {
+		return &integrityError{References: references}
+	}
+
 	return nil
 }
 
-func (l *loader) loadFile(ctx context.Context, filePath string) error {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return fmt.Errorf("unable to open file %s: %w", filePath, err)
+// fileTableName returns the name of the table of a file, files are named after their table.
+func fileTableName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), extensionFormat)
+}
+
+// deferredColumns returns the columns of foreign keys referencing the table itself or a table loaded later,
+// only nullable columns of tables with a key can be deferred.
+func deferredColumns(tableNames []string, schema map[string]config.Schema) map[string][]string {
+	positions := make(map[string]int, len(tableNames))
+	for i, tableName := range tableNames {
+		if _, ok := schema[tableName]; ok {
+			positions[tableName] = i
+		}
 	}
-	defer file.Close()
 
-	content, err := io.ReadAll(file)
-	if err != nil {
-		return fmt.Errorf("unable to read file %s: %w", filePath, err)
+	columns := make(map[string][]string)
+	for i, tableName := range tableNames {
+		table := schema[tableName].Table
+		if len(table.KeyColumnNames()) == 0 {
+			continue
+		}
+
+		for _, foreignKey := range table.ForeignKeys {
+			position, ok := positions[foreignKey.ReferencedTableName]
+			if !ok || position < i || !nullable(table, foreignKey.Columns.ColumnNames()) {
+				continue
+			}
+
+			for _, columnName := range foreignKey.Columns.ColumnNames() {
+				if !containsString(columns[tableName], columnName) {
+					columns[tableName] = append(columns[tableName], columnName)
+				}
+			}
+		}
+	}
+
+	return columns
+}
+
+// forwardReferences returns the columns of foreign keys referencing a table loaded later which are not
+// deferred, as table.column.
+func forwardReferences(tableNames []string, schema map[string]config.Schema, deferred map[string][]string) []string {
+	positions := make(map[string]int, len(tableNames))
+	for i, tableName := range tableNames {
+		if _, ok := schema[tableName]; ok {
+			positions[tableName] = i
+		}
+	}
+
+	var columnNames []string
+	for i, tableName := range tableNames {
+		for _, foreignKey := range schema[tableName].Table.ForeignKeys {
+			if position, ok := positions[foreignKey.ReferencedTableName]; !ok || position <= i {
+				continue
+			}
+
+			for _, columnName := range foreignKey.Columns.ColumnNames() {
+				name := tableName + "." + columnName
+				if !containsString(deferred[tableName], columnName) && !containsString(columnNames, name) {
+					columnNames = append(columnNames, name)
+				}
+			}
+		}
+	}
+
+	return columnNames
+}
+
+// nullable returns true when every column is nullable.
+func nullable(table dialect.Table, columnNames []string) bool {
+	for _, columnName := range columnNames {
+		if !table.Columns.Get(columnName).Nullable {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitDeferred returns the rows to insert with the deferred columns set to NULL and the rows
+// setting them once inserted, identified by the key of the table.
+func splitDeferred(table dialect.Table, data []map[string]interface{}, columnNames []string) ([]map[string]interface{}, []map[string]interface{}) {
+	var (
+		keyColumnNames = table.KeyColumnNames()
+		inserts        = make([]map[string]interface{}, len(data))
+		updates        = make([]map[string]interface{}, 0)
+	)
+	for i := range data {
+		inserts[i] = data[i]
+
+		var update map[string]interface{}
+		for _, columnName := range columnNames {
+			if data[i][columnName] == nil {
+				continue
+			}
+
+			if update == nil {
+				inserts[i] = make(map[string]interface{}, len(data[i]))
+				for k, v := range data[i] {
+					inserts[i][k] = v
+				}
+
+				update = make(map[string]interface{}, len(keyColumnNames)+len(columnNames))
+				for _, keyColumnName := range keyColumnNames {
+					update[keyColumnName] = data[i][keyColumnName]
+				}
+			}
+
+			inserts[i][columnName] = nil
+			update[columnName] = data[i][columnName]
+		}
+
+		if update != nil {
+			updates = append(updates, update)
+		}
+	}
+
+	return inserts, updates
+}
+
+// sortFiles sorts files in dependency order, the files of referenced tables are loaded first.
+// Files are named after their table, files of unknown tables are loaded last.
+func (l *loader) sortFiles(files []string) []string {
+	filesByTable := make(map[string]string, len(files))
+	tableNames := make([]string, 0, len(files))
+	others := make([]string, 0)
+	for _, file := range files {
+		tableName := fileTableName(file)
+		if _, ok := l.schema[tableName]; !ok {
+			others = append(others, file)
+			continue
+		}
+
+		filesByTable[tableName] = file
+		tableNames = append(tableNames, tableName)
+	}
+
+	sorted := make([]string, 0, len(files))
+	for _, tableName := range sortTables(tableNames, l.schema) {
+		sorted = append(sorted, filesByTable[tableName])
+	}
+
+	return append(sorted, others...)
+}
+
+// sortTables returns the tables sorted so that referenced tables come before the tables referencing them,
+// self-references are ignored and cycles are broken by the first of their tables by name.
+func sortTables(tableNames []string, schema map[string]config.Schema) []string {
+	tableNames = append([]string(nil), tableNames...)
+	sort.Strings(tableNames)
+
+	selected := make(map[string]struct{}, len(tableNames))
+	for _, tableName := range tableNames {
+		selected[tableName] = struct{}{}
+	}
+
+	dependencies := make(map[string]map[string]struct{}, len(tableNames))
+	for _, tableName := range tableNames {
+		dependencies[tableName] = make(map[string]struct{})
+		for _, foreignKey := range schema[tableName].Table.ForeignKeys {
+			referenced := foreignKey.ReferencedTableName
+			if _, ok := selected[referenced]; ok && referenced != tableName {
+				dependencies[tableName][referenced] = struct{}{}
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(tableNames))
+	loaded := make(map[string]struct{}, len(tableNames))
+	for len(sorted) < len(tableNames) {
+		progress := false
+		for _, tableName := range tableNames {
+			if _, ok := loaded[tableName]; ok || !loadable(dependencies[tableName], loaded) {
+				continue
+			}
+
+			sorted = append(sorted, tableName)
+			loaded[tableName] = struct{}{}
+			progress = true
+		}
+
+		if progress {
+			continue
+		}
+
+		// The remaining tables reference each other, the first table of a cycle breaks it.
+		for _, tableName := range tableNames {
+			if _, ok := loaded[tableName]; !ok && cyclic(tableName, dependencies, loaded) {
+				sorted = append(sorted, tableName)
+				loaded[tableName] = struct{}{}
+				break
+			}
+		}
+	}
+
+	return sorted
+}
+
+// cyclic returns true when a table depends on itself through tables which are not loaded yet.
+func cyclic(tableName string, dependencies map[string]map[string]struct{}, loaded map[string]struct{}) bool {
+	var (
+		visited = make(map[string]struct{})
+		stack   = []string{tableName}
+	)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for dependency := range dependencies[current] {
+			if dependency == tableName {
+				return true
+			}
+
+			if _, ok := loaded[dependency]; ok {
+				continue
+			}
+
+			if _, ok := visited[dependency]; !ok {
+				visited[dependency] = struct{}{}
+				stack = append(stack, dependency)
+			}
+		}
+	}
+
+	return false
+}
+
+// loadable returns true when every dependency has been loaded.
+func loadable(dependencies map[string]struct{}, loaded map[string]struct{}) bool {
+	for tableName := range dependencies {
+		if _, ok := loaded[tableName]; !ok {
+			return false
+		}
 	}
 
-	var payload jsonPayload
-	if err := json.Unmarshal(content, &payload); err != nil {
-		return fmt.Errorf("unable to decode %s: %w", content, err)
+	return true
+}
+
+// refreshMaterializedViews refreshes the materialized views once their underlying tables are loaded.
+func (l *loader) refreshMaterializedViews(ctx context.Context) error {
+	refresher, ok := l.dialect.(dialect.MaterializedViewRefresher)
+	if !ok {
+		return nil
+	}
+
+	tableNames := make([]string, 0)
+	for tableName := range l.schema {
+		if l.schema[tableName].Table.Kind == dialect.TableKindMaterializedView {
+			tableNames = append(tableNames, tableName)
+		}
+	}
+	sort.Strings(tableNames)
+
+	for _, tableName := range tableNames {
+		l.logger.Info("Refresh materialized view", zap.String("table", tableName))
+
+		if err := refresher.RefreshMaterializedView(ctx, l.schema[tableName].Table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// conflictStrategy returns the conflict strategy of a table, the strategy of the load by default.
+func (l *loader) conflictStrategy(schema config.Schema) dialect.ConflictStrategy {
+	if schema.Conflict == "" {
+		return l.conflict
 	}
 
-	return l.loadJSON(ctx, l.schema[payload.TableName], payload)
+	return schema.Conflict
 }
 
-func (l *loader) loadJSON(ctx context.Context, schema config.Schema, payload jsonPayload) error {
-	return l.dialect.BulkInsert(ctx, schema.Table, payload.Data)
+// conflicts returns a ConflictError reporting the rows of a file conflicting with existing rows on the key
+// of their table. Batches are committed on their own, the fail strategy checks every batch of a file before
+// the first one is inserted so it inserts nothing.
+func (l *loader) conflicts(ctx context.Context, file string) error {
+	var conflictErr *dialect.ConflictError
+	if err := streamFile(file, l.batchSize, func(tableName string, rows []map[string]interface{}) error {
+		table := l.schema[tableName].Table
+
+		// Remapped rows are inserted with new keys.
+		keyColumnNames := table.KeyColumnNames()
+		if _, ok := remappedColumn(table); (ok && l.remap) || len(keyColumnNames) == 0 {
+			return nil
+		}
+
+		keys := make([][]interface{}, 0, len(rows))
+		for _, row := range rows {
+			if values := rowValues(row, keyColumnNames); !hasNil(values) {
+				keys = append(keys, values)
+			}
+		}
+
+		existing, err := existingRows(ctx, l.dialect, tableName, keyColumnNames, keys)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve existing rows of %s: %w", tableName, err)
+		}
+
+		for _, key := range keys {
+			if _, ok := existing[existingKey(key)]; !ok {
+				continue
+			}
+
+			if conflictErr == nil {
+				conflictErr = &dialect.ConflictError{TableName: tableName, ColumnNames: keyColumnNames}
+			}
+			conflictErr.Keys = append(conflictErr.Keys, key)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if conflictErr != nil {
+		l.logConflicts(conflictErr)
+		return conflictErr
+	}
+
+	return nil
+}
+
+func (l *loader) logConflicts(conflictErr *dialect.ConflictError) {
+	l.logger.Error("Rows conflict with existing rows",
+		zap.String("table", conflictErr.TableName),
+		zap.Strings("columns", conflictErr.ColumnNames),
+		zap.Int("count", len(conflictErr.Keys)),
+		zap.String("keys", fmt.Sprint(conflictErr.Keys)))
+}
+
+// loadJSON loads a batch of rows, offset is the index of its first row in its file.
+func (l *loader) loadJSON(ctx context.Context, schema config.Schema, payload jsonPayload, offset int) error {
+	opts := dialect.InsertOptions{
+		Conflict:      l.conflictStrategy(schema),
+		UpdateColumns: schema.UpdateColumns,
+	}
+
+	var (
+		keyColumnNames = schema.Table.KeyColumnNames()
+		keys           [][]interface{}
+	)
+	if l.journal != nil {
+		if len(keyColumnNames) > 0 {
+			opts.Inserted = func(key []interface{}) {
+				keys = append(keys, key)
+			}
+		} else if offset == 0 {
+			l.logger.Warn("Rows of a table without key are not journaled", zap.String("table", payload.TableName))
+		}
+	}
+
+	err := l.dialect.BulkInsert(ctx, schema.Table, payload.Data, opts)
+	if err == nil && l.journal != nil {
+		err = l.journal.record(payload.TableName, keyColumnNames, keys)
+	}
+
+	var (
+		conflictErr *dialect.ConflictError
+		rowErr      *dialect.RowError
+	)
+	switch {
+	case errors.As(err, &conflictErr):
+		l.logConflicts(conflictErr)
+	case errors.As(err, &rowErr):
+		rowErr.Index += offset
+		l.logger.Error("Row cannot be loaded",
+			zap.String("table", rowErr.TableName),
+			zap.Int("index", rowErr.Index),
+			zap.String("row", fmt.Sprint(rowErr.Row)),
+			zap.Error(rowErr.Err))
+	}
+
+	return err
 }
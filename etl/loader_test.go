@@ -0,0 +1,444 @@
+package etl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect"
+)
+
+func writePayload(t *testing.T, outputPath string, payload jsonPayload) {
+	payload.Count = len(payload.Data)
+
+	content, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(outputPath, payload.TableName+extensionFormat), content, 0644))
+}
+
+func TestLoad(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 1, "username": "conflict", "email": "conflict@ulule.com"},
+			{"id": 4, "username": "loader", "email": "loader@ulule.com"},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 3, "name": "loader", "user_id": 4},
+		},
+	})
+
+	engine, d := newTestEngine(t, "fixture.json", config.Config{})
+	require.NoError(t, engine.Load(ctx, outputPath))
+
+	users := d.Rows("user")
+	require.Len(t, users, 4)
+	assert.Equal(t, "thoas", users[0]["username"])
+	assert.Equal(t, "loader", users[3]["username"])
+
+	projects := d.Rows("project")
+	require.Len(t, projects, 3)
+	assert.Equal(t, float64(4), projects[2]["user_id"])
+
+	assert.ElementsMatch(t, []string{"user", "project"}, d.Inserts())
+}
+
+func TestLoadMissingDirectory(t *testing.T) {
+	engine, _ := newTestEngine(t, "fixture.json", config.Config{})
+
+	assert.Error(t, engine.Load(context.Background(), filepath.Join(t.TempDir(), "missing")))
+}
+
+func TestLoadWithoutKeys(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "log",
+		Data: []map[string]interface{}{
+			{"user_id": 3, "action": "login"},
+			{"user_id": 1, "action": "signup"},
+		},
+	})
+
+	engine, d := newTestEngine(t, "backers.json", config.Config{
+		Schema: []config.Schema{
+			{TableName: "log", Conflict: dialect.ConflictSkipDuplicates},
+		},
+	})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Len(t, d.Rows("log"), 4)
+
+	engine, d = newTestEngine(t, "backers.json", config.Config{
+		Schema: []config.Schema{
+			{TableName: "log", Conflict: dialect.ConflictAppend},
+		},
+	})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Len(t, d.Rows("log"), 5)
+
+	_, err := NewEngineWithDialect(ctx, config.Config{
+		Schema: []config.Schema{
+			{TableName: "log", Conflict: "unknown"},
+		},
+	}, d, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestLoadViews(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 3, "username": "mover"},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project_stats",
+		Data: []map[string]interface{}{
+			{"project_id": 3, "user_id": 3, "backers": 0},
+		},
+	})
+
+	engine, d := newTestEngine(t, "views.json", config.Config{})
+	require.NoError(t, engine.Load(ctx, outputPath))
+
+	// Views are skipped and materialized views are refreshed once tables are loaded.
+	assert.Equal(t, []string{"user"}, d.Inserts())
+	assert.Len(t, d.Rows("project_stats"), 2)
+	assert.Equal(t, []string{"leaderboard"}, d.Refreshes())
+}
+
+func TestLoadConflictStrategies(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 1, "username": "florent", "email": "updated@ulule.com"},
+		},
+	})
+
+	// The table strategy takes precedence over the strategy of the run.
+	engine, d := newTestEngine(t, "fixture.json", config.Config{
+		Conflict: dialect.ConflictFail,
+		Schema: []config.Schema{
+			{TableName: "user", Conflict: dialect.ConflictUpdate, UpdateColumns: []string{"username"}},
+		},
+	})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Equal(t, "florent", d.Rows("user")[0]["username"])
+	assert.Equal(t, "florent@ulule.com", d.Rows("user")[0]["email"])
+
+	engine, d = newTestEngine(t, "fixture.json", config.Config{Conflict: dialect.ConflictFail})
+	err := engine.Load(ctx, outputPath)
+
+	var conflictErr *dialect.ConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, "user", conflictErr.TableName)
+	assert.Equal(t, "thoas", d.Rows("user")[0]["username"])
+
+	// A conflict in the second batch fails before the first batch is inserted.
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 4, "username": "loader", "email": "loader@ulule.com"},
+			{"id": 1, "username": "florent", "email": "updated@ulule.com"},
+		},
+	})
+
+	engine, d = newTestEngine(t, "fixture.json", config.Config{Conflict: dialect.ConflictFail, BatchSize: 1})
+	require.ErrorAs(t, engine.Load(ctx, outputPath), &conflictErr)
+	assert.Equal(t, [][]interface{}{{float64(1)}}, conflictErr.Keys)
+	assert.Empty(t, d.Inserts())
+	assert.Len(t, d.Rows("user"), 3)
+
+	_, err = NewEngineWithDialect(ctx, config.Config{Conflict: "unknown"}, d, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func TestLoadModes(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "reward",
+		Data: []map[string]interface{}{
+			{"id": 1, "price": 40, "project_id": 3},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 3, "name": "loader", "user_id": 4},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 4, "username": "loader", "email": "loader@ulule.com"},
+		},
+	})
+
+	// Files are loaded in dependency order whatever their names.
+	engine, d := newTestEngine(t, "fixture.json", config.Config{})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Equal(t, []string{"user", "project", "reward"}, d.Inserts())
+	require.Len(t, d.Loads(), 1)
+	assert.Equal(t, dialect.LoadMode(""), d.Loads()[0].Mode)
+	assert.Equal(t, []string{"user", "project", "reward"}, tableNames(d.Loads()[0].Tables))
+
+	// The reward conflicts with an existing row, the deferred load is rolled back.
+	engine, d = newTestEngine(t, "fixture.json", config.Config{
+		Conflict: dialect.ConflictFail,
+		LoadMode: dialect.LoadModeDeferred,
+	})
+	require.Error(t, engine.Load(ctx, outputPath))
+	require.Len(t, d.Loads(), 1)
+	assert.Equal(t, dialect.LoadModeDeferred, d.Loads()[0].Mode)
+	assert.Equal(t, []string{"user", "project"}, d.Inserts())
+	assert.Len(t, d.Rows("user"), 3)
+	assert.Len(t, d.Rows("project"), 2)
+
+	_, err := NewEngineWithDialect(ctx, config.Config{LoadMode: "unknown"}, d, zap.NewNop())
+	assert.Error(t, err)
+}
+
+func tableNames(tables dialect.Tables) []string {
+	names := make([]string, len(tables))
+	for i := range tables {
+		names[i] = tables[i].Name
+	}
+
+	return names
+}
+
+func TestSortTables(t *testing.T) {
+	schema := map[string]config.Schema{
+		"user": {Table: dialect.Table{Name: "user", ForeignKeys: []dialect.ForeignKey{
+			{ReferencedTableName: "user"},
+		}}},
+		"project": {Table: dialect.Table{Name: "project", ForeignKeys: []dialect.ForeignKey{
+			{ReferencedTableName: "user"},
+			{ReferencedTableName: "reward"},
+		}}},
+		"reward": {Table: dialect.Table{Name: "reward", ForeignKeys: []dialect.ForeignKey{
+			{ReferencedTableName: "project"},
+		}}},
+		"backer": {Table: dialect.Table{Name: "backer", ForeignKeys: []dialect.ForeignKey{
+			{ReferencedTableName: "reward"},
+		}}},
+	}
+
+	// project and reward reference each other, the first one by name breaks the cycle.
+	assert.Equal(t, []string{"user", "project", "reward", "backer"},
+		sortTables([]string{"backer", "reward", "project", "user"}, schema))
+	assert.Equal(t, []string{"backer", "user"}, sortTables([]string{"user", "backer"}, schema))
+}
+
+func TestLoadCycles(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 1, "username": "thoas", "referrer_id": 2},
+			{"id": 2, "username": "ulule", "referrer_id": nil},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 1, "user_id": 1, "featured_reward_id": 1},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "reward",
+		Data: []map[string]interface{}{
+			{"id": 1, "project_id": 1},
+		},
+	})
+
+	// Constraints are checked in ordered mode, nullable columns of cycles are set once every file is loaded.
+	engine, d := newTestEngine(t, "cycles.json", config.Config{LoadMode: dialect.LoadModeOrdered})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Equal(t, []string{"user", "project", "reward"}, d.Inserts())
+	assert.Equal(t, []string{"user", "project"}, d.Updates())
+
+	users := d.Rows("user")
+	require.Len(t, users, 2)
+	assert.Equal(t, float64(2), users[0]["referrer_id"])
+	assert.Nil(t, users[1]["referrer_id"])
+	assert.Equal(t, float64(1), d.Rows("project")[0]["featured_reward_id"])
+
+	// The auto mode falls back to the ordered mode when constraints cannot be deferred.
+	engine, d = newTestEngine(t, "cycles.json", config.Config{})
+	d.SetAutoMode(dialect.LoadModeOrdered)
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Equal(t, []string{"user", "project"}, d.Updates())
+
+	// Other modes do not check foreign keys while loading.
+	engine, d = newTestEngine(t, "cycles.json", config.Config{LoadMode: dialect.LoadModeReplica})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Empty(t, d.Updates())
+	assert.Equal(t, float64(2), d.Rows("user")[0]["referrer_id"])
+
+	// Deferred rows are spilled and updated in batches.
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 1, "username": "thoas", "referrer_id": 2},
+			{"id": 2, "username": "ulule", "referrer_id": 1},
+		},
+	})
+
+	engine, d = newTestEngine(t, "cycles.json", config.Config{LoadMode: dialect.LoadModeOrdered, BatchSize: 1})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Equal(t, []string{"user", "user", "project"}, d.Updates())
+
+	users = d.Rows("user")
+	require.Len(t, users, 2)
+	assert.Equal(t, float64(2), users[0]["referrer_id"])
+	assert.Equal(t, float64(1), users[1]["referrer_id"])
+}
+
+func TestLoadAutoOrdered(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 1, "featured_reward_id": 1},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "reward",
+		Data: []map[string]interface{}{
+			{"id": 1, "project_id": 1},
+		},
+	})
+
+	// project and reward reference each other with columns which are not nullable.
+	engine, d := newTestEngine(t, "mutual.json", config.Config{})
+	d.SetAutoMode(dialect.LoadModeOrdered)
+
+	// The load fails before inserting any row.
+	err := engine.Load(ctx, outputPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "project.featured_reward_id")
+	assert.Empty(t, d.Inserts())
+
+	// An explicit ordered mode is attempted.
+	engine, d = newTestEngine(t, "mutual.json", config.Config{LoadMode: dialect.LoadModeOrdered})
+
+	var rowErr *dialect.RowError
+	require.ErrorAs(t, engine.Load(ctx, outputPath), &rowErr)
+	assert.Equal(t, "project", rowErr.TableName)
+}
+
+func TestLoadAtomic(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 4, "username": "loader", "email": "loader@ulule.com"},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 3, "name": "loader", "user_id": 4},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "reward",
+		Data: []map[string]interface{}{
+			{"id": 4, "price": 40, "project_id": 3},
+			{"id": 5, "price": 50, "project_id": 9},
+		},
+	})
+
+	// The second reward references a project which does not exist, every table is rolled back.
+	engine, d := newTestEngine(t, "fixture.json", config.Config{
+		LoadMode: dialect.LoadModeOrdered,
+		Atomic:   true,
+	})
+	err := engine.Load(ctx, outputPath)
+
+	var rowErr *dialect.RowError
+	require.ErrorAs(t, err, &rowErr)
+	assert.Equal(t, "reward", rowErr.TableName)
+	assert.Equal(t, 1, rowErr.Index)
+	assert.Equal(t, float64(5), rowErr.Row["id"])
+
+	require.Len(t, d.Loads(), 1)
+	assert.Equal(t, dialect.LoadModeOrdered, d.Loads()[0].Mode)
+	assert.True(t, d.Loads()[0].Atomic)
+	assert.Equal(t, []string{"user", "project"}, d.Inserts())
+	assert.Len(t, d.Rows("user"), 3)
+	assert.Len(t, d.Rows("project"), 2)
+	assert.Len(t, d.Rows("reward"), 3)
+
+	// Nothing is journaled.
+	_, err = os.Stat(filepath.Join(outputPath, journalFileName))
+	assert.True(t, os.IsNotExist(err))
+
+	// Without atomic loads, the batches committed before the failing one are kept.
+	engine, d = newTestEngine(t, "fixture.json", config.Config{
+		LoadMode:  dialect.LoadModeOrdered,
+		BatchSize: 1,
+	})
+	require.ErrorAs(t, engine.Load(ctx, outputPath), &rowErr)
+	assert.Equal(t, 1, rowErr.Index)
+
+	assert.Equal(t, []string{"user", "project", "reward"}, d.Inserts())
+	assert.Len(t, d.Rows("user"), 4)
+	assert.Len(t, d.Rows("project"), 3)
+
+	rewards := d.Rows("reward")
+	require.Len(t, rewards, 4)
+	assert.Equal(t, float64(4), rewards[3]["id"])
+
+	// The committed batches are journaled.
+	entries, err := readJournal(filepath.Join(outputPath, journalFileName))
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, journalEntry{TableName: "reward", ColumnNames: []string{"id"}, Keys: [][]interface{}{{float64(4)}}}, entries[2])
+}
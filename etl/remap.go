@@ -0,0 +1,134 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect"
+)
+
+// keyMapping maps the keys of loaded rows to the keys allocated in the target database by table name,
+// keys are indexed by their string representation since rows decoded from JSON contain float64 values.
+type keyMapping map[string]map[string]interface{}
+
+// remappedColumn returns the column of a table whose keys are remapped: a single column primary key
+// owning a sequence.
+func remappedColumn(table dialect.Table) (string, bool) {
+	if !table.Insertable() || len(table.PrimaryKeys) != 1 {
+		return "", false
+	}
+
+	columnName := table.PrimaryKeys[0].Name
+	if _, ok := table.Sequence(columnName); !ok {
+		return "", false
+	}
+
+	return columnName, true
+}
+
+// allocateKeys allocates a new key from the target sequences for every row of the remapped tables.
+func (l *loader) allocateKeys(ctx context.Context, files []string) (keyMapping, error) {
+	allocator, ok := l.dialect.(dialect.KeyAllocator)
+	if !ok {
+		return nil, fmt.Errorf("unable to remap keys: dialect does not allocate keys")
+	}
+
+	mapping := make(keyMapping)
+	for _, file := range files {
+		table := l.schema[fileTableName(file)].Table
+		columnName, ok := remappedColumn(table)
+		if !ok {
+			continue
+		}
+
+		// Only the keys of the rows are kept in memory.
+		keys := make([]string, 0)
+		if err := streamFile(file, l.batchSize, func(tableName string, rows []map[string]interface{}) error {
+			for i := range rows {
+				value := rows[i][columnName]
+				if value == nil {
+					continue
+				}
+
+				key := fmt.Sprint(value)
+				if _, ok := mapping[table.Name][key]; ok {
+					continue
+				}
+
+				if mapping[table.Name] == nil {
+					mapping[table.Name] = make(map[string]interface{})
+				}
+				mapping[table.Name][key] = nil
+				keys = append(keys, key)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("unable to load file %s: %w", file, err)
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		values, err := allocator.AllocateKeys(ctx, table, columnName, len(keys))
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range keys {
+			mapping[table.Name][keys[i]] = values[i]
+		}
+
+		l.logger.Info("Remap keys",
+			zap.String("table", table.Name),
+			zap.String("column", columnName),
+			zap.Int("count", len(keys)))
+	}
+
+	return mapping, nil
+}
+
+// remapRows rewrites the remapped keys of rows and the foreign key columns referencing them,
+// values referencing rows which are not loaded are left untouched.
+func remapRows(schema map[string]config.Schema, table dialect.Table, data []map[string]interface{}, mapping keyMapping) {
+	columnName, remapped := remappedColumn(table)
+
+	for i := range data {
+		if remapped {
+			remapValue(data[i], columnName, mapping[table.Name])
+		}
+
+		for _, foreignKey := range table.ForeignKeys {
+			referencedColumnName, ok := remappedColumn(schema[foreignKey.ReferencedTableName].Table)
+			if !ok {
+				continue
+			}
+
+			for _, reference := range foreignKey.Columns {
+				// A remapped key which is also a foreign key keeps its own mapping.
+				if remapped && reference.ColumnName == columnName {
+					continue
+				}
+
+				if reference.ReferencedColumnName == referencedColumnName {
+					remapValue(data[i], reference.ColumnName, mapping[foreignKey.ReferencedTableName])
+				}
+			}
+		}
+	}
+}
+
+func remapValue(row map[string]interface{}, columnName string, keys map[string]interface{}) {
+	value := row[columnName]
+	if value == nil {
+		return
+	}
+
+	if key, ok := keys[fmt.Sprint(value)]; ok {
+		row[columnName] = key
+	}
+}
@@ -0,0 +1,61 @@
+package etl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/config"
+)
+
+func TestLoadRemap(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	// Keys collide with the rows of the fixture.
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 1, "username": "remap", "email": "remap@ulule.com"},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 1, "name": "remap", "user_id": 1},
+			{"id": 2, "name": "existing", "user_id": 2},
+		},
+	})
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "reward",
+		Data: []map[string]interface{}{
+			{"id": 1, "price": 40, "project_id": 1},
+		},
+	})
+
+	engine, d := newTestEngine(t, "fixture.json", config.Config{Remap: true})
+	require.NoError(t, engine.Load(ctx, outputPath))
+
+	users := d.Rows("user")
+	require.Len(t, users, 4)
+	assert.Equal(t, "thoas", users[0]["username"])
+	assert.Equal(t, int64(4), users[3]["id"])
+	assert.Equal(t, "remap", users[3]["username"])
+
+	projects := d.Rows("project")
+	require.Len(t, projects, 4)
+	assert.Equal(t, int64(3), projects[2]["id"])
+	assert.Equal(t, int64(4), projects[2]["user_id"])
+	// The user is not part of the dump, the existing one is referenced.
+	assert.Equal(t, int64(4), projects[3]["id"])
+	assert.Equal(t, float64(2), projects[3]["user_id"])
+
+	rewards := d.Rows("reward")
+	require.Len(t, rewards, 4)
+	assert.Equal(t, int64(4), rewards[3]["id"])
+	assert.Equal(t, int64(3), rewards[3]["project_id"])
+}
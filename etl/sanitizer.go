@@ -35,32 +35,14 @@ func newSanitizer(localeKey string, schema map[string]config.Schema) *sanitizer
 	}
 }
 
-func (s *sanitizer) sanitize(table dialect.Table, rows entry) []map[string]interface{} {
-	var (
-		results = make([]map[string]interface{}, 0)
-		index   = make(map[interface{}]struct{})
-		schema  = s.schema[table.Name]
-	)
-
-	for _, values := range rows {
-		for j := range values {
-			value := values[j]
-			primaryKey := value[table.PrimaryKeyColumnName()]
-			if _, ok := index[primaryKey]; ok {
-				continue
-			}
-
-			if len(schema.Columns) == 0 {
-				results = append(results, value)
-			} else {
-				results = append(results, s.sanitizeValues(schema, value))
-			}
-
-			index[primaryKey] = struct{}{}
-		}
+// sanitize sanitizes the columns of a row declared in the schema of its table.
+func (s *sanitizer) sanitize(table dialect.Table, row map[string]interface{}) map[string]interface{} {
+	schema := s.schema[table.Name]
+	if len(schema.Columns) == 0 {
+		return row
 	}
 
-	return results
+	return s.sanitizeValues(schema, row)
 }
 
 func (s *sanitizer) fakeValue(column config.Column, value interface{}) interface{} {
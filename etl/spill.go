@@ -0,0 +1,179 @@
+package etl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// spillExtension is the extension of spill files, it differs from extensionFormat so they are never loaded.
+const spillExtension = ".jsonl"
+
+// spill writes the rows of every table to a file as they are fetched, one JSON row per line,
+// so extracted rows are never held in memory.
+type spill struct {
+	path  string
+	files map[string]*spillFile
+	// useNumber decodes numbers as json.Number so they are written back without losing precision,
+	// they are decoded as float64 like the rows of loaded files otherwise.
+	useNumber bool
+}
+
+type spillFile struct {
+	path   string
+	file   *os.File
+	writer *bufio.Writer
+	count  int
+}
+
+// newSpill creates a temporary spill directory in path, or in the default directory for temporary
+// files when path is empty, removed by Close.
+func newSpill(path string, useNumber bool) (*spill, error) {
+	dir, err := os.MkdirTemp(path, ".spill-")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create spill directory in %s: %w", path, err)
+	}
+
+	return &spill{
+		path:      dir,
+		files:     make(map[string]*spillFile),
+		useNumber: useNumber,
+	}, nil
+}
+
+// write appends a row to the spill file of its table.
+func (s *spill) write(tableName string, row map[string]interface{}) error {
+	line, err := encodeLine(tableName, row)
+	if err != nil {
+		return err
+	}
+
+	return s.writeLine(tableName, line)
+}
+
+// encodeLine encodes a row as a line of a spill file.
+func encodeLine(tableName string, row map[string]interface{}) ([]byte, error) {
+	content, err := json.Marshal(row)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode row of %s in JSON: %w", tableName, err)
+	}
+
+	return append(content, '\n'), nil
+}
+
+// writeLine appends a row encoded by encodeLine to the spill file of its table.
+func (s *spill) writeLine(tableName string, line []byte) error {
+	f, ok := s.files[tableName]
+	if !ok {
+		filePath := filepath.Join(s.path, tableName+spillExtension)
+		file, err := os.Create(filePath)
+		if err != nil {
+			return fmt.Errorf("unable to create spill file %s: %w", filePath, err)
+		}
+
+		f = &spillFile{path: filePath, file: file, writer: bufio.NewWriter(file)}
+		s.files[tableName] = f
+	}
+
+	if _, err := f.writer.Write(line); err != nil {
+		return fmt.Errorf("unable to write spill file %s: %w", f.path, err)
+	}
+	f.count++
+
+	return nil
+}
+
+// count returns the number of rows spilled for a table.
+func (s *spill) count(tableName string) int {
+	if f, ok := s.files[tableName]; ok {
+		return f.count
+	}
+
+	return 0
+}
+
+// tableNames returns the names of the tables with spilled rows.
+func (s *spill) tableNames() []string {
+	tableNames := make([]string, 0, len(s.files))
+	for tableName := range s.files {
+		tableNames = append(tableNames, tableName)
+	}
+	sort.Strings(tableNames)
+
+	return tableNames
+}
+
+// read calls fn with the spilled rows of a table in fetch order.
+func (s *spill) read(tableName string, fn func(row map[string]interface{}) error) error {
+	f, ok := s.files[tableName]
+	if !ok {
+		return nil
+	}
+
+	if err := f.writer.Flush(); err != nil {
+		return fmt.Errorf("unable to write spill file %s: %w", f.path, err)
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return fmt.Errorf("unable to open spill file %s: %w", f.path, err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	if s.useNumber {
+		decoder.UseNumber()
+	}
+	for decoder.More() {
+		var row map[string]interface{}
+		if err := decoder.Decode(&row); err != nil {
+			return fmt.Errorf("unable to decode spill file %s: %w", f.path, err)
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readBatches calls fn with the spilled rows of a table in batches of at most batchSize rows.
+func (s *spill) readBatches(tableName string, batchSize int, fn func(rows []map[string]interface{}) error) error {
+	rows := make([]map[string]interface{}, 0, batchSize)
+	if err := s.read(tableName, func(row map[string]interface{}) error {
+		rows = append(rows, row)
+		if len(rows) < batchSize {
+			return nil
+		}
+
+		err := fn(rows)
+		rows = make([]map[string]interface{}, 0, batchSize)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return fn(rows)
+}
+
+// Close closes and removes the spill files.
+func (s *spill) Close() error {
+	for _, f := range s.files {
+		f.file.Close()
+	}
+
+	if err := os.RemoveAll(s.path); err != nil {
+		return fmt.Errorf("unable to remove spill directory %s: %w", s.path, err)
+	}
+
+	return nil
+}
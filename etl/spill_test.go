@@ -0,0 +1,82 @@
+package etl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpill(t *testing.T) {
+	outputPath := t.TempDir()
+
+	s, err := newSpill(outputPath, true)
+	require.NoError(t, err)
+
+	require.NoError(t, s.write("user", map[string]interface{}{"id": int64(9007199254740993), "username": "thoas"}))
+	require.NoError(t, s.write("project", map[string]interface{}{"id": 1}))
+	require.NoError(t, s.write("user", map[string]interface{}{"id": 2, "username": "ulule"}))
+
+	assert.Equal(t, []string{"project", "user"}, s.tableNames())
+	assert.Equal(t, 2, s.count("user"))
+	assert.Equal(t, 0, s.count("reward"))
+
+	// Spill files are never loaded.
+	files, err := listFiles(outputPath)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	rows := make([]map[string]interface{}, 0)
+	require.NoError(t, s.read("user", func(row map[string]interface{}) error {
+		rows = append(rows, row)
+		return nil
+	}))
+	require.Len(t, rows, 2)
+	assert.Equal(t, json.Number("9007199254740993"), rows[0]["id"])
+	assert.Equal(t, "ulule", rows[1]["username"])
+
+	batches := make([]int, 0)
+	require.NoError(t, s.readBatches("user", 1, func(rows []map[string]interface{}) error {
+		batches = append(batches, len(rows))
+		return nil
+	}))
+	assert.Equal(t, []int{1, 1}, batches)
+
+	require.NoError(t, s.Close())
+	_, err = os.Stat(s.path)
+	assert.True(t, os.IsNotExist(err))
+
+	entries, err := os.ReadDir(outputPath)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// Numbers are decoded as float64 like loaded files unless they are kept as json.Number.
+	s, err = newSpill("", false)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.write("user", map[string]interface{}{"id": 1}))
+	require.NoError(t, s.read("user", func(row map[string]interface{}) error {
+		assert.Equal(t, float64(1), row["id"])
+		return nil
+	}))
+
+	require.NoError(t, writeFile(filepath.Join(outputPath, "user.json"), "user", len(rows), func(write func(row map[string]interface{}) error) error {
+		for i := range rows {
+			if err := write(rows[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	content, err := os.ReadFile(filepath.Join(outputPath, "user.json"))
+	require.NoError(t, err)
+
+	expected, err := json.MarshalIndent(jsonPayload{TableName: "user", Count: len(rows), Data: rows}, "", "\t")
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(content))
+}
@@ -0,0 +1,172 @@
+package etl
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// defaultBatchSize is the number of rows decoded and loaded at once when the batch size is not configured.
+const defaultBatchSize = 1000
+
+// streamFile decodes the rows of a file incrementally and calls fn with batches of at most batchSize rows,
+// the whole file is never held in memory. Files without table_name before data are named after the file.
+func streamFile(filePath string, batchSize int, fn func(tableName string, rows []map[string]interface{}) error) error {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("unable to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(bufio.NewReader(file))
+	if err := expectDelim(decoder, '{'); err != nil {
+		return fmt.Errorf("unable to decode %s: %w", filePath, err)
+	}
+
+	tableName := fileTableName(filePath)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("unable to decode %s: %w", filePath, err)
+		}
+
+		switch token {
+		case "table_name":
+			if err := decoder.Decode(&tableName); err != nil {
+				return fmt.Errorf("unable to decode table_name of %s: %w", filePath, err)
+			}
+		case "data":
+			// Errors of fn are returned as is, only decoding errors are wrapped.
+			var fnErr error
+			if err := streamRows(decoder, batchSize, func(rows []map[string]interface{}) error {
+				fnErr = fn(tableName, rows)
+				return fnErr
+			}); err != nil {
+				if fnErr != nil {
+					return fnErr
+				}
+
+				return fmt.Errorf("unable to decode data of %s: %w", filePath, err)
+			}
+		default:
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return fmt.Errorf("unable to decode %s: %w", filePath, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// streamRows decodes a JSON array of rows in batches, a null array contains no rows.
+func streamRows(decoder *json.Decoder, batchSize int, fn func(rows []map[string]interface{}) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected array, got %v", token)
+	}
+
+	rows := make([]map[string]interface{}, 0, batchSize)
+	for decoder.More() {
+		var row map[string]interface{}
+		if err := decoder.Decode(&row); err != nil {
+			return err
+		}
+
+		rows = append(rows, row)
+		if len(rows) == batchSize {
+			if err := fn(rows); err != nil {
+				return err
+			}
+			rows = make([]map[string]interface{}, 0, batchSize)
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	if len(rows) > 0 {
+		return fn(rows)
+	}
+
+	return nil
+}
+
+func expectDelim(decoder *json.Decoder, expected json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %v, got %v", expected, token)
+	}
+
+	return nil
+}
+
+// writeFile writes a payload to a file row by row, in the same indented format as json.MarshalIndent,
+// rows calls write for every row of the payload.
+func writeFile(filePath string, tableName string, count int, rows func(write func(row map[string]interface{}) error) error) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("unable to write JSON output to %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	header, err := json.Marshal(tableName)
+	if err != nil {
+		return fmt.Errorf("unable to encode in JSON: %w", err)
+	}
+
+	writer := bufio.NewWriter(file)
+	fmt.Fprintf(writer, "{\n\t\"table_name\": %s,\n\t\"count\": %d,\n\t\"data\": [", header, count)
+
+	written := 0
+	if err := rows(func(row map[string]interface{}) error {
+		content, err := json.MarshalIndent(row, "\t\t", "\t")
+		if err != nil {
+			return fmt.Errorf("unable to encode in JSON: %w", err)
+		}
+
+		if written > 0 {
+			writer.WriteString(",")
+		}
+		writer.WriteString("\n\t\t")
+		writer.Write(content)
+		written++
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if written > 0 {
+		writer.WriteString("\n\t")
+	}
+	writer.WriteString("]\n}")
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("unable to write JSON output to %s: %w", filePath, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to write JSON output to %s: %w", filePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package etl
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ulule/mover/config"
+)
+
+func TestStreamFile(t *testing.T) {
+	outputPath := t.TempDir()
+
+	stream := func(content string, batchSize int) ([]string, [][]map[string]interface{}, error) {
+		filePath := filepath.Join(outputPath, "user"+extensionFormat)
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+		var (
+			tableNames []string
+			batches    [][]map[string]interface{}
+		)
+		err := streamFile(filePath, batchSize, func(tableName string, rows []map[string]interface{}) error {
+			tableNames = append(tableNames, tableName)
+			batches = append(batches, rows)
+			return nil
+		})
+
+		return tableNames, batches, err
+	}
+
+	tableNames, batches, err := stream(`{"table_name": "project", "count": 3, "data": [{"id": 1}, {"id": 2}, {"id": 3}]}`, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"project", "project"}, tableNames)
+	assert.Equal(t, [][]map[string]interface{}{
+		{{"id": float64(1)}, {"id": float64(2)}},
+		{{"id": float64(3)}},
+	}, batches)
+
+	// Files without table_name before data are named after the file.
+	tableNames, batches, err = stream(`{"data": [{"id": 1}], "extra": {"key": [1, 2]}}`, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, tableNames)
+	assert.Len(t, batches, 1)
+
+	_, batches, err = stream(`{"table_name": "user", "count": 0, "data": null}`, 2)
+	require.NoError(t, err)
+	assert.Empty(t, batches)
+
+	_, _, err = stream(`{"table_name": "user", "data": [{"id": 1}`, 2)
+	assert.Error(t, err)
+
+	_, _, err = stream(`[]`, 2)
+	assert.Error(t, err)
+}
+
+func TestLoadBatches(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "user",
+		Data: []map[string]interface{}{
+			{"id": 4, "username": "loader", "email": "loader@ulule.com"},
+			{"id": 5, "username": "stream", "email": "stream@ulule.com"},
+			{"id": 6, "username": "batch", "email": "batch@ulule.com"},
+		},
+	})
+
+	engine, d := newTestEngine(t, "fixture.json", config.Config{BatchSize: 2})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Equal(t, []string{"user", "user"}, d.Inserts())
+	assert.Len(t, d.Rows("user"), 6)
+}
@@ -2,6 +2,8 @@ package etl
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,12 +13,15 @@ import (
 	"regexp"
 	"strings"
 
+	lk "github.com/ulule/loukoum/v3"
+	"github.com/ulule/loukoum/v3/stmt"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect"
 )
 
-var sqlSelectRegexp = regexp.MustCompile(`^(?i)SELECT (?P<columns>.*[^T]) FROM (?P<table>\w+).*`)
+var sqlSelectRegexp = regexp.MustCompile(`^(?i)SELECT (?P<columns>.*[^T]) FROM (?P<table>[\w."]+).*`)
 
 func regexpNamedParams(reg *regexp.Regexp, value string) map[string]string {
 	match := reg.FindStringSubmatch(value)
@@ -39,32 +44,23 @@ func getQueryTable(query string) string {
 	matches := regexpNamedParams(sqlSelectRegexp, query)
 
 	if val, ok := matches["table"]; ok {
-		return val
+		return strings.ReplaceAll(val, `"`, "")
 	}
 
 	return ""
 }
 
-func extractFilenames(schema config.Schema, rows entry) []string {
+// extractFilenames returns the URLs of the files of a row to download.
+func extractFilenames(schema config.Schema, row map[string]interface{}) []string {
 	filenames := make([]string, 0)
 	for i := range schema.Columns {
 		if schema.Columns[i].Download == nil {
 			continue
 		}
 
-		for j := range rows {
-			for _, row := range rows[j] {
-				for k, v := range row {
-					if k != schema.Columns[i].Name {
-						continue
-					}
-
-					v, ok := v.(string)
-					if ok && v != "" {
-						filenames = append(filenames, schema.Columns[i].Download.HTTP.URL(v))
-					}
-				}
-			}
+		v, ok := row[schema.Columns[i].Name].(string)
+		if ok && v != "" {
+			filenames = append(filenames, schema.Columns[i].Download.HTTP.URL(v))
 		}
 	}
 
@@ -162,3 +158,156 @@ func cacheKey(query string, args ...interface{}) string {
 
 	return cacheKey
 }
+
+// relationKey returns the identity of the rows of a table matching values on columns
+// (e.g. "backer(user_id, project_id) = 1, 2").
+func relationKey(tableName string, columnNames []string, values ...interface{}) string {
+	return fmt.Sprintf("%s(%s) = %s", tableName, strings.Join(columnNames, ", "), joinValues(values))
+}
+
+// rowKey returns the identity of a row from the primary key or a unique key of its table,
+// rows without any non NULL key are identified by a hash of all their values.
+func rowKey(table dialect.Table, row map[string]interface{}) string {
+	columnNames := table.KeyColumnNames()
+	values := rowValues(row, columnNames)
+
+	if len(columnNames) == 0 || hasNil(values) {
+		hash := sha1.Sum([]byte(fmt.Sprintf("%v", row)))
+		return relationKey(table.Name, []string{"*"}, hex.EncodeToString(hash[:]))
+	}
+
+	return relationKey(table.Name, columnNames, values...)
+}
+
+func joinValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i := range values {
+		parts[i] = fmt.Sprintf("%v", values[i])
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func hasNil(values []interface{}) bool {
+	for i := range values {
+		if values[i] == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectRows returns a query selecting the rows of a table matching all the column values.
+func selectRows(tableName string, columnNames []string, values []interface{}) (string, []interface{}) {
+	builder := lk.Select(lk.Raw("*")).
+		From(tableName).
+		Where(lk.Condition(columnNames[0]).Equal(values[0]))
+
+	for i := 1; i < len(columnNames); i++ {
+		builder = builder.And(lk.Condition(columnNames[i]).Equal(values[i]))
+	}
+
+	return builder.Query()
+}
+
+// selectRowsIn returns a query selecting the rows of a table matching any of the values on columns,
+// a single column is matched with "= ANY($1)" when arrays are bound to a single parameter.
+func selectRowsIn(tableName string, columnNames []string, values [][]interface{}, arrays bool) (string, []interface{}) {
+	if len(values) == 1 {
+		return selectRows(tableName, columnNames, values[0])
+	}
+
+	builder := lk.Select(lk.Raw("*")).From(tableName)
+
+	if len(columnNames) == 1 {
+		keys := make([]interface{}, len(values))
+		for i := range values {
+			keys[i] = values[i][0]
+		}
+
+		if arrays {
+			query, _ := builder.Where(lk.Condition(columnNames[0]).Equal(lk.Raw("ANY($1)"))).Query()
+			return query, []interface{}{keys}
+		}
+
+		return builder.Where(lk.Condition(columnNames[0]).In(keys...)).Query()
+	}
+
+	for i := range values {
+		var condition stmt.Expression = lk.Condition(columnNames[0]).Equal(values[i][0])
+		for j := 1; j < len(columnNames); j++ {
+			condition = lk.And(condition, lk.Condition(columnNames[j]).Equal(values[i][j]))
+		}
+
+		if i == 0 {
+			builder = builder.Where(condition)
+		} else {
+			builder = builder.Or(condition)
+		}
+	}
+
+	return builder.Query()
+}
+
+// existingRows returns the existing rows of a table matching the given keys on columns indexed by
+// existingKey, keys are fetched by chunks as the extractor does.
+func existingRows(ctx context.Context, d dialect.Dialect, tableName string, columnNames []string, keys [][]interface{}) (map[string]map[string]interface{}, error) {
+	binder, ok := d.(dialect.ArrayBinder)
+	arrays := ok && binder.BindsArrays()
+
+	chunkSize := maxFetchedKeys
+	if len(columnNames) > 1 {
+		chunkSize = maxFetchedCompositeKeys
+	}
+
+	rows := make(map[string]map[string]interface{}, len(keys))
+	for i := 0; i < len(keys); i += chunkSize {
+		end := i + chunkSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		query, args := selectRowsIn(tableName, columnNames, keys[i:end], arrays)
+		results, err := d.ResultSet(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range results {
+			rows[existingKey(rowValues(row, columnNames))] = row
+		}
+	}
+
+	return rows, nil
+}
+
+// existingKey returns the identity of key values, existing and loaded values are compared as decoded
+// from JSON since loaded rows are decoded from JSON.
+func existingKey(values []interface{}) string {
+	normalized := make([]interface{}, len(values))
+	for i := range values {
+		normalized[i] = normalizeValue(values[i])
+	}
+
+	return joinValues(normalized)
+}
+
+func rowValues(row map[string]interface{}, columnNames []string) []interface{} {
+	values := make([]interface{}, len(columnNames))
+	for i := range columnNames {
+		values[i] = row[columnNames[i]]
+	}
+
+	return values
+}
+
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -10,4 +10,24 @@ func TestGetQueryTable(t *testing.T) {
 	assert.Equal(t, "ulule_project", getQueryTable("select * from ulule_project"))
 	assert.Equal(t, "ulule_project", getQueryTable("SELECT * FROM ulule_project"))
 	assert.Equal(t, "ulule_project", getQueryTable("SELECT one, two, three FROM ulule_project"))
+	assert.Equal(t, "billing.invoice", getQueryTable("SELECT * FROM billing.invoice WHERE id = 1"))
+	assert.Equal(t, "billing.invoice", getQueryTable(`SELECT * FROM "billing"."invoice"`))
+}
+
+func TestSelectRowsIn(t *testing.T) {
+	query, args := selectRowsIn("user", []string{"id"}, [][]interface{}{{1}}, true)
+	assert.Equal(t, `SELECT * FROM "user" WHERE ("id" = $1)`, query)
+	assert.Equal(t, []interface{}{1}, args)
+
+	query, args = selectRowsIn("user", []string{"id"}, [][]interface{}{{1}, {2}}, true)
+	assert.Equal(t, `SELECT * FROM "user" WHERE ("id" = ANY($1))`, query)
+	assert.Equal(t, []interface{}{[]interface{}{1, 2}}, args)
+
+	query, args = selectRowsIn("user", []string{"id"}, [][]interface{}{{1}, {2}}, false)
+	assert.Equal(t, `SELECT * FROM "user" WHERE ("id" IN ($1, $2))`, query)
+	assert.Equal(t, []interface{}{1, 2}, args)
+
+	query, args = selectRowsIn("backer", []string{"user_id", "project_id"}, [][]interface{}{{3, 1}, {3, 2}}, true)
+	assert.Equal(t, `SELECT * FROM "backer" WHERE ((("user_id" = $1) AND ("project_id" = $2)) OR (("user_id" = $3) AND ("project_id" = $4)))`, query)
+	assert.Equal(t, []interface{}{3, 1, 3, 2}, args)
 }
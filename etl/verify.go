@@ -0,0 +1,133 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+	"github.com/ulule/mover/dialect"
+)
+
+// maxReportedRows is the number of rows reported for every foreign key referencing rows which do not exist.
+const maxReportedRows = 10
+
+// danglingReference contains the rows of a table referencing rows which do not exist through a foreign key.
+type danglingReference struct {
+	TableName  string
+	ForeignKey dialect.ForeignKey
+	Count      int64
+	Rows       []map[string]interface{}
+}
+
+// integrityError is returned when rows reference rows which do not exist.
+type integrityError struct {
+	References []danglingReference
+}
+
+func (e *integrityError) Error() string {
+	references := make([]string, len(e.References))
+	for i, reference := range e.References {
+		references[i] = fmt.Sprintf("%d rows of %s(%s) reference rows of %s which do not exist",
+			reference.Count, reference.TableName, strings.Join(reference.ForeignKey.Columns.ColumnNames(), ", "),
+			reference.ForeignKey.ReferencedTableName)
+	}
+
+	return strings.Join(references, ", ")
+}
+
+// Verify reports the rows of the tables of an output directory referencing rows which do not exist.
+func (l *loader) Verify(ctx context.Context, outputPath string) error {
+	tableNames := make([]string, 0)
+	if outputPath == "" {
+		for tableName := range l.schema {
+			tableNames = append(tableNames, tableName)
+		}
+		sort.Strings(tableNames)
+	} else {
+		files, err := listFiles(outputPath)
+		if err != nil {
+			return err
+		}
+
+		for _, file := range l.sortFiles(files) {
+			tableNames = append(tableNames, fileTableName(file))
+		}
+	}
+
+	references, err := l.verifyTables(ctx, tableNames)
+	if err != nil {
+		return err
+	}
+
+	if len(references) > 0 {
+		return &integrityError{References: references}
+	}
+
+	return nil
+}
+
+// verifyTables checks the foreign keys of tables and logs the rows referencing rows which do not exist.
+func (l *loader) verifyTables(ctx context.Context, tableNames []string) ([]danglingReference, error) {
+	checker, ok := l.dialect.(dialect.IntegrityChecker)
+	if !ok {
+		l.logger.Warn("Skip verification unsupported by the dialect")
+		return nil, nil
+	}
+
+	level := zap.WarnLevel
+	if l.verify == config.VerifyStrict {
+		level = zap.ErrorLevel
+	}
+
+	references := make([]danglingReference, 0)
+	for _, tableName := range tableNames {
+		schema, ok := l.schema[tableName]
+		if !ok || !schema.Table.Insertable() {
+			continue
+		}
+
+		for _, foreignKey := range schema.Table.ForeignKeys {
+			count, rows, err := checker.DanglingReferences(ctx, schema.Table, foreignKey, maxReportedRows)
+			if err != nil {
+				return nil, fmt.Errorf("unable to verify %s: %w", tableName, err)
+			}
+
+			if count == 0 {
+				continue
+			}
+
+			// Rows are reported by their key and their foreign key values.
+			columnNames := make([]string, 0)
+			columnNames = append(columnNames, schema.Table.KeyColumnNames()...)
+			columnNames = append(columnNames, foreignKey.Columns.ColumnNames()...)
+
+			keys := make([][]interface{}, len(rows))
+			for i := range rows {
+				keys[i] = rowValues(rows[i], columnNames)
+			}
+
+			if ce := l.logger.Check(level, "Rows reference rows which do not exist"); ce != nil {
+				ce.Write(
+					zap.String("table", tableName),
+					zap.String("foreign_key", foreignKey.Name),
+					zap.Strings("columns", foreignKey.Columns.ColumnNames()),
+					zap.String("referenced_table", foreignKey.ReferencedTableName),
+					zap.Int64("count", count),
+					zap.String("rows", fmt.Sprint(keys)))
+			}
+
+			references = append(references, danglingReference{
+				TableName:  tableName,
+				ForeignKey: foreignKey,
+				Count:      count,
+				Rows:       rows,
+			})
+		}
+	}
+
+	return references, nil
+}
@@ -0,0 +1,55 @@
+package etl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/ulule/mover/config"
+)
+
+func TestVerify(t *testing.T) {
+	var (
+		outputPath = t.TempDir()
+		ctx        = context.Background()
+	)
+
+	writePayload(t, outputPath, jsonPayload{
+		TableName: "project",
+		Data: []map[string]interface{}{
+			{"id": 3, "name": "orphan", "user_id": 9},
+			{"id": 4, "name": "loader", "user_id": 1},
+		},
+	})
+
+	// Dangling references are logged by default.
+	engine, d := newTestEngine(t, "fixture.json", config.Config{})
+	require.NoError(t, engine.Verify(ctx, ""))
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Len(t, d.Rows("project"), 4)
+
+	err := engine.Verify(ctx, outputPath)
+
+	var integrityErr *integrityError
+	require.ErrorAs(t, err, &integrityErr)
+	require.Len(t, integrityErr.References, 1)
+	assert.Equal(t, "project", integrityErr.References[0].TableName)
+	assert.Equal(t, "user", integrityErr.References[0].ForeignKey.ReferencedTableName)
+	assert.Equal(t, int64(1), integrityErr.References[0].Count)
+	assert.Equal(t, float64(3), integrityErr.References[0].Rows[0]["id"])
+
+	// Strict atomic loads are rolled back.
+	engine, d = newTestEngine(t, "fixture.json", config.Config{Verify: config.VerifyStrict, Atomic: true})
+	require.ErrorAs(t, engine.Load(ctx, outputPath), &integrityErr)
+	assert.Len(t, d.Rows("project"), 2)
+
+	engine, d = newTestEngine(t, "fixture.json", config.Config{Verify: config.VerifyOff})
+	require.NoError(t, engine.Load(ctx, outputPath))
+	assert.Len(t, d.Rows("project"), 4)
+
+	_, err = NewEngineWithDialect(ctx, config.Config{Verify: "unknown"}, d, zap.NewNop())
+	assert.Error(t, err)
+}